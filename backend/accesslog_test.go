@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONAccessLogLine(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "json")
+	var logs bytes.Buffer
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 4), nil
+		},
+		accessLog: &logs,
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/models", nil)
+	req.Header.Set(requestIDHeader, "trace-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one access log line, got %q", logs.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("access log line is not JSON: %v (%q)", err, lines[0])
+	}
+	for _, key := range []string{"timestamp", "method", "path", "status", "latency_ms", "client_ip", "request_id", "bytes"} {
+		if _, ok := entry[key]; !ok {
+			t.Fatalf("expected key %q in %v", key, entry)
+		}
+	}
+	if entry["method"] != "GET" || entry["path"] != "/models" || entry["status"] != float64(200) {
+		t.Fatalf("unexpected request fields: %v", entry)
+	}
+	if entry["request_id"] != "trace-42" {
+		t.Fatalf("expected request ID trace-42, got %v", entry["request_id"])
+	}
+	if entry["bytes"] != float64(w.Body.Len()) {
+		t.Fatalf("expected bytes=%d, got %v", w.Body.Len(), entry["bytes"])
+	}
+}
+
+func TestTextAccessLogIsDefault(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "")
+	var logs bytes.Buffer
+	router := newTestRouter(appDeps{accessLog: &logs})
+
+	performRequest(router, http.MethodGet, "/metrics", "")
+	if !strings.Contains(logs.String(), "[GIN]") || json.Valid(bytes.TrimSpace(logs.Bytes())) {
+		t.Fatalf("expected gin text format, got %q", logs.String())
+	}
+}
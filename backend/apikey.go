@@ -0,0 +1,31 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// requireAPIKey rejects requests without a matching X-API-Key header when
+// API_KEY is set, whatever their method. It is attached by route, to every
+// handler that changes state, runs the engine or may fetch a remote model;
+// plain reads such as GET /models, and the health probes load balancers poll,
+// stay public. An unset API_KEY disables the check for local development.
+func (s *server) requireAPIKey() gin.HandlerFunc {
+	want := []byte(s.cfg.apiKey)
+	return func(c *gin.Context) {
+		if len(want) == 0 {
+			c.Next()
+			return
+		}
+		got := []byte(c.GetHeader(apiKeyHeader))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			respondError(c, newAPIError(http.StatusUnauthorized, codeUnauthorized, "missing or invalid API key"))
+			return
+		}
+		c.Next()
+	}
+}
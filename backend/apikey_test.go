@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newAPIKeyRouter(t *testing.T) http.Handler {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+	})
+}
+
+func postWithAPIKey(router http.Handler, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/compute", strings.NewReader(`{"start":0,"end":4,"model":"mesh.obj"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIKeyRequiredForEngineRoutes(t *testing.T) {
+	t.Setenv("API_KEY", "s3cret")
+	router := newAPIKeyRouter(t)
+
+	cases := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{name: "missing", key: "", status: http.StatusUnauthorized},
+		{name: "wrong", key: "guess", status: http.StatusUnauthorized},
+		{name: "correct", key: "s3cret", status: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := postWithAPIKey(router, tc.key)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusUnauthorized {
+				if body := decodeJSONBody(t, w); body["code"] != string(codeUnauthorized) {
+					t.Fatalf("expected UNAUTHORIZED, got %v", body)
+				}
+			}
+		})
+	}
+
+	for _, path := range []string{"/models", "/v1/models", "/health", "/cache/stats"} {
+		if w := performRequest(router, http.MethodGet, path, ""); w.Code == http.StatusUnauthorized {
+			t.Fatalf("expected GET %s to stay public, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestAPIKeyRequiredByRouteWhateverTheMethod(t *testing.T) {
+	t.Setenv("API_KEY", "s3cret")
+	router := newAPIKeyRouter(t)
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/compute?start=0&end=4&model=mesh.obj"},
+		{http.MethodGet, "/v1/compute_mst?model=mesh.obj"},
+		{http.MethodPost, "/v1/compute_batch"},
+		{http.MethodPost, "/v1/warmup"},
+		{http.MethodPost, "/v1/validate"},
+		{http.MethodPost, "/models"},
+		{http.MethodDelete, "/models/mesh.obj"},
+		{http.MethodGet, "/selftest"},
+		{http.MethodGet, "/engine/version"},
+		{http.MethodDelete, "/cache"},
+	} {
+		if w := performRequest(router, route.method, route.path, "{}"); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected %s %s to require the key, got %d", route.method, route.path, w.Code)
+		}
+	}
+}
+
+func TestAPIKeyUnsetLeavesRoutesOpen(t *testing.T) {
+	t.Setenv("API_KEY", "")
+	router := newAPIKeyRouter(t)
+
+	if w := postWithAPIKey(router, ""); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 without API_KEY configured, got %d", w.Code)
+	}
+}
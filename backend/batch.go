@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchPairs = 100
+
+// batchModes maps the "mode" field of batch and stream requests onto engine
+// routes; the single-computation routes are registered from the same entries.
+// "bfs" is the hop-count shortest path for unweighted models, "bellman"
+// handles negative edge weights and "ksp" returns the k shortest paths.
+// "mst" (the minimum spanning tree) and "diameter" (the longest shortest path
+// and every vertex's eccentricity) cover the whole model. The file each mode
+// writes comes from resultFileForMode.
+var batchModes = map[string]engineRoute{
+	"":          {resultKeys: pathResultKeys, sameNodePath: true, directed: true},
+	"dijkstra":  {resultKeys: pathResultKeys, sameNodePath: true, directed: true},
+	"analytics": {mode: "analytics", resultKeys: curveResultKeys, weighted: true, surface: true},
+	"heat":      {mode: "heat", resultKeys: curveResultKeys, arrayResult: true, extraArgs: heatArgs, paginate: true, surface: true},
+	"astar":     {mode: "astar", resultKeys: pathResultKeys, extraArgs: astarArgs, sameNodePath: true, directed: true},
+	"bfs":       {mode: "bfs", resultKeys: pathResultKeys, sameNodePath: true, directed: true},
+	"bellman":   {mode: "bellman", resultKeys: pathResultKeys, checkResult: checkNegativeCycle, sameNodePath: true, directed: true},
+	"ksp":       {mode: "ksp", arrayResult: true, extraArgs: kspArgs, directed: true},
+	"mst":       {mode: "mst", resultKeys: mstResultKeys, wholeGraph: true},
+	"diameter":  {mode: "diameter", resultKeys: diameterResultKeys, wholeGraph: true},
+}
+
+type batchPair struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type batchRequest struct {
+	Model     string      `json:"model"`
+	Mode      string      `json:"mode"`
+	Heuristic string      `json:"heuristic,omitempty"`
+	K         *int        `json:"k,omitempty"`
+	Radius    *float64    `json:"radius,omitempty"`
+	Weight    *float64    `json:"weight,omitempty"`
+	Directed  bool        `json:"directed,omitempty"`
+	Weighted  bool        `json:"weighted,omitempty"`
+	Engine    string      `json:"engine,omitempty"`
+	Pairs     []batchPair `json:"pairs"`
+}
+
+// outcome is the result of one computation inside a multi-computation
+// response; exactly one of Result and Error is set, and Status mirrors what
+// the single-computation endpoint would have returned.
+type outcome struct {
+	Status int             `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *errorResponse  `json:"error,omitempty"`
+}
+
+type batchResult struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+	outcome
+}
+
+// handleBatch computes every pair against one model, running up to
+// MAX_CONCURRENCY engines at once. Per-pair failures are reported in place so
+// one bad pair does not fail the whole batch.
+func (s *server) handleBatch(c *gin.Context) {
+	var req batchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errRequestBody(err))
+		return
+	}
+	route, ok := batchModes[strings.ToLower(strings.TrimSpace(req.Mode))]
+	if !ok {
+		respondError(c, newAPIError(400, codeInvalidInput,
+			fmt.Sprintf("unsupported mode %q", req.Mode)))
+		return
+	}
+	if len(req.Pairs) == 0 || len(req.Pairs) > maxBatchPairs {
+		respondError(c, newAPIError(400, codeInvalidInput,
+			fmt.Sprintf("pairs must contain between 1 and %d entries", maxBatchPairs)).
+			withDetail("pairs", len(req.Pairs)))
+		return
+	}
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+
+	requestID := requestIDFrom(c)
+	results := make([]batchResult, len(req.Pairs))
+	s.forEachConcurrently(len(req.Pairs), func(i int) {
+		start, end := req.Pairs[i].Start, req.Pairs[i].End
+		pair := computeRequest{
+			Start:     &start,
+			End:       &end,
+			Model:     req.Model,
+			Heuristic: req.Heuristic,
+			K:         req.K,
+			Radius:    req.Radius,
+			Weight:    req.Weight,
+			Directed:  req.Directed,
+			Weighted:  req.Weighted,
+			Engine:    req.Engine,
+		}
+		results[i] = batchResult{
+			Start:   start,
+			End:     end,
+			outcome: s.computeOutcome(c.Request.Context(), requestID, projectRoot, pair, route),
+		}
+	})
+
+	body, err := json.Marshal(results)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	writePayload(c, http.StatusOK, body)
+}
+
+// forEachConcurrently calls fn for 0..n-1 on up to MAX_CONCURRENCY
+// goroutines and waits for all of them.
+func (s *server) forEachConcurrently(n int, fn func(i int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(s.cfg.maxConcurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+func (s *server) computeOutcome(ctx context.Context, requestID, projectRoot string, req computeRequest, route engineRoute) outcome {
+	job, err := s.buildJob(ctx, requestID, projectRoot, req, route)
+	var res engineResult
+	if err == nil {
+		res, err = s.runEngine(ctx, job)
+	}
+	if err != nil {
+		apiErr := toAPIError(err)
+		return outcome{
+			Status: apiErr.status,
+			Error:  &errorResponse{Code: apiErr.code, Message: apiErr.message, Details: apiErr.details},
+		}
+	}
+	return outcome{Status: http.StatusOK, Result: res.payload}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBatchReportsPerPairResults(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			if args[0] == "7" {
+				return []byte("solver diverged"), errors.New("exit status 2")
+			}
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_batch", `{
+		"model": "mesh.obj",
+		"mode": "heat",
+		"pairs": [{"start":0,"end":1},{"start":7,"end":2},{"start":3,"end":42},{"start":4,"end":5}]
+	}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	var results []batchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("failed to decode batch results: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+
+	expected := []struct {
+		start, end, status int
+		code               errorCode
+	}{
+		{0, 1, http.StatusOK, ""},
+		{7, 2, http.StatusInternalServerError, codeEngineFailed},
+		{3, 42, http.StatusUnprocessableEntity, codeInvalidInput},
+		{4, 5, http.StatusOK, ""},
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Start != want.start || got.End != want.end || got.Status != want.status {
+			t.Fatalf("result %d: expected %d->%d status %d, got %+v", i, want.start, want.end, want.status, got)
+		}
+		if want.code == "" {
+			if string(got.Result) != `{"path":[],"curves":[]}` || got.Error != nil {
+				t.Fatalf("result %d: expected inline payload, got %+v", i, got)
+			}
+			continue
+		}
+		if got.Error == nil || got.Error.Code != want.code || got.Result != nil {
+			t.Fatalf("result %d: expected error %s, got %+v", i, want.code, got)
+		}
+	}
+}
+
+func TestBatchRejectsInvalidRequests(t *testing.T) {
+	tooMany := make([]string, maxBatchPairs+1)
+	for i := range tooMany {
+		tooMany[i] = `{"start":0,"end":1}`
+	}
+
+	cases := map[string]string{
+		"no pairs":     `{"model":"mesh.obj","pairs":[]}`,
+		"too many":     `{"model":"mesh.obj","pairs":[` + strings.Join(tooMany, ",") + `]}`,
+		"unknown mode": `{"model":"mesh.obj","mode":"teleport","pairs":[{"start":0,"end":1}]}`,
+		"invalid json": `{"model":`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return newTestProject(t, "mesh.obj", 16), nil
+				},
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					t.Fatalf("runEngine should not be called for a rejected batch")
+					return nil, nil
+				},
+			})
+			w := performRequest(router, http.MethodPost, "/compute_batch", body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestBatchRespectsMaxConcurrency(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "2")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+
+	var running, peak atomic.Int32
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	pairs := make([]string, 8)
+	for i := range pairs {
+		pairs[i] = `{"start":0,"end":` + string(rune('1'+i)) + `}`
+	}
+	w := performRequest(router, http.MethodPost, "/compute_batch",
+		`{"model":"mesh.obj","pairs":[`+strings.Join(pairs, ",")+`]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("expected at most 2 concurrent engines, saw %d", got)
+	}
+}
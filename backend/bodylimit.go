@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultMaxBodyBytes      = 1 << 20
+	defaultMaxBatchBodyBytes = 8 << 20
+)
+
+// limitBody caps how much of the request body handlers may read. Reads past
+// the limit fail with *http.MaxBytesError, which errRequestBody reports as 413.
+func limitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// requireJSON rejects POST requests whose body is not declared as JSON, so
+// form posts and bodiless requests get a clear 415 instead of a bind error.
+// Parameters such as charset=utf-8 are allowed.
+func requireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		contentType := c.GetHeader("Content-Type")
+		if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType != "application/json" {
+			respondError(c, newAPIError(http.StatusUnsupportedMediaType, codeUnsupportedMedia,
+				"request body must be JSON (Content-Type: application/json)").
+				withDetail("contentType", contentType))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// errRequestBody describes a request body that could not be bound.
+func errRequestBody(err error) *apiError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return newAPIError(http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("request body exceeds the %d byte limit", tooLarge.Limit)).
+			withDetail("limitBytes", tooLarge.Limit).wrap(err)
+	}
+	return newAPIError(http.StatusBadRequest, codeInvalidInput, err.Error()).wrap(err)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOversizedBodyReturnsPayloadTooLarge(t *testing.T) {
+	t.Setenv("MAX_BODY_BYTES", "64")
+	engineCalls := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			engineCalls++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+
+	body := fmt.Sprintf(`{"start":0,"end":1,"model":"%s.obj"}`, strings.Repeat("a", 100))
+	for _, route := range []string{"/compute", "/compute_stream"} {
+		w := performRequest(router, http.MethodPost, route, body)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("%s: expected status 413, got %d", route, w.Code)
+		}
+		resp := decodeJSONBody(t, w)
+		if resp["code"] != string(codePayloadTooLarge) || resp["message"] != "request body exceeds the 64 byte limit" {
+			t.Fatalf("%s: unexpected error body %v", route, resp)
+		}
+	}
+
+	if engineCalls != 0 {
+		t.Fatalf("expected no engine runs for oversized bodies, got %d", engineCalls)
+	}
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected a body under the limit to be accepted, got %d", w.Code)
+	}
+}
+
+func TestBatchBodyLimitOverridesDefault(t *testing.T) {
+	t.Setenv("MAX_BODY_BYTES", "64")
+	t.Setenv("MAX_BATCH_BODY_BYTES", "4096")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+
+	pairs := strings.TrimSuffix(strings.Repeat(`{"start":0,"end":1},`, 10), ",")
+	w := performRequest(router, http.MethodPost, "/compute_batch", `{"model":"mesh.obj","pairs":[`+pairs+`]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected batch under its own limit to succeed, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	pairs = strings.TrimSuffix(strings.Repeat(`{"start":0,"end":1},`, 300), ",")
+	w = performRequest(router, http.MethodPost, "/compute_batch", `{"model":"mesh.obj","pairs":[`+pairs+`]}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413 past the batch limit, got %d", w.Code)
+	}
+}
+
+func TestComputeRoutesRequireJSONContentType(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{name: "json", contentType: "application/json", body: `{"start":0,"end":1,"model":"mesh.obj"}`, want: http.StatusOK},
+		{name: "json with charset", contentType: "application/json; charset=utf-8", body: `{"start":0,"end":1,"model":"mesh.obj"}`, want: http.StatusOK},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: "start=0&end=1&model=mesh.obj", want: http.StatusUnsupportedMediaType},
+		{name: "missing", body: "", want: http.StatusUnsupportedMediaType},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/compute", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Fatalf("expected status %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+			if tc.want != http.StatusUnsupportedMediaType {
+				return
+			}
+			resp := decodeJSONBody(t, w)
+			if resp["code"] != string(codeUnsupportedMedia) {
+				t.Fatalf("expected code %s, got %v", codeUnsupportedMedia, resp["code"])
+			}
+			if errorDetails(t, resp)["contentType"] != tc.contentType {
+				t.Fatalf("expected the rejected content type in details, got %v", resp)
+			}
+		})
+	}
+
+	// Query-string requests carry no body and are unaffected.
+	if w := performRequest(router, http.MethodGet, "/compute?start=0&end=1&model=mesh.obj", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected GET to succeed without a JSON body, got %d", w.Code)
+	}
+}
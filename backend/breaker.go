@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerWindow    = time.Minute
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// breakerFaults are the failures that point at the engine itself rather than
+// at the request, so only they count towards opening the breaker. Engines
+// killed by shutdown report CANCELED and are left out.
+var breakerFaults = map[errorCode]bool{
+	codeEngineFailed:     true,
+	codeEngineCrashed:    true,
+	codeEngineTimeout:    true,
+	codeEngineDown:       true,
+	codeResultUnreadable: true,
+	codeResultCorrupt:    true,
+	codeResultMissing:    true,
+}
+
+// circuitBreaker stops engine runs after threshold faults within window. Once
+// open it rejects runs for cooldown, then lets a single trial run through: a
+// success closes it again and a fault reopens it for another cooldown.
+type circuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	window       time.Duration
+	cooldown     time.Duration
+	faults       int
+	firstFault   time.Time
+	openUntil    time.Time
+	trialRunning bool
+	now          func() time.Time
+}
+
+func newCircuitBreaker(threshold int, window, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		window:    window,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a run may start and whether it is the half-open
+// trial, which the caller passes back to record. When it may not start, it
+// also reports how long until the breaker next lets a trial run through.
+func (b *circuitBreaker) allow() (ok, trial bool, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return true, false, 0
+	}
+	if wait := b.openUntil.Sub(b.now()); wait > 0 {
+		return false, false, wait
+	}
+	if b.trialRunning {
+		return false, false, b.cooldown
+	}
+	b.trialRunning = true
+	return true, true, 0
+}
+
+// record reports the outcome of a run that allow let through and whether it
+// opened the breaker; trial is what allow returned for the run. Errors that
+// are not engine faults, such as a client disconnecting, leave the count
+// alone. While the breaker is open only the trial decides whether it closes
+// or reopens: a run admitted before it opened finishing late changes nothing.
+func (b *circuitBreaker) record(err error, trial bool) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trialRunning = false
+	} else if !b.openUntil.IsZero() {
+		return false
+	}
+	var apiErr *apiError
+	if err == nil {
+		b.faults = 0
+		b.openUntil = time.Time{}
+		return false
+	}
+	if !errors.As(err, &apiErr) || !breakerFaults[apiErr.code] {
+		return false
+	}
+
+	now := b.now()
+	if trial {
+		b.openUntil = now.Add(b.cooldown)
+		return true
+	}
+	if b.faults == 0 || now.Sub(b.firstFault) > b.window {
+		b.faults = 0
+		b.firstFault = now
+	}
+	b.faults++
+	if b.faults < b.threshold {
+		return false
+	}
+	b.faults = 0
+	b.openUntil = now.Add(b.cooldown)
+	return true
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(3, time.Minute, 10*time.Second)
+	b.now = func() time.Time { return now }
+	fault := newAPIError(500, codeEngineFailed, "engine failed")
+
+	for i := 0; i < 2; i++ {
+		b.record(fault, false)
+	}
+	if b.record(errCanceled(context.Canceled), false) {
+		t.Fatalf("a canceled request must not open the breaker")
+	}
+	if !b.record(fault, false) {
+		t.Fatalf("expected the third fault to open the breaker")
+	}
+	if ok, _, wait := b.allow(); ok || wait != 10*time.Second {
+		t.Fatalf("expected rejection for the 10s cooldown, got ok=%v wait=%s", ok, wait)
+	}
+
+	now = now.Add(10 * time.Second)
+	ok, trial, _ := b.allow()
+	if !ok || !trial {
+		t.Fatalf("expected a trial run after the cooldown")
+	}
+	if ok, _, _ := b.allow(); ok {
+		t.Fatalf("expected only one trial run at a time")
+	}
+	if !b.record(fault, trial) {
+		t.Fatalf("expected a failed trial to reopen the breaker")
+	}
+
+	now = now.Add(10 * time.Second)
+	_, trial, _ = b.allow()
+	b.record(nil, trial)
+	if ok, trial, _ := b.allow(); !ok || trial {
+		t.Fatalf("expected a successful trial to close the breaker")
+	}
+}
+
+func TestOnlyTheTrialDecidesAHalfOpenBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(1, time.Minute, 10*time.Second)
+	b.now = func() time.Time { return now }
+	fault := newAPIError(500, codeEngineFailed, "engine failed")
+
+	// Three runs start while the breaker is closed; one faults and opens it.
+	_, lateSuccess, _ := b.allow()
+	_, lateFault, _ := b.allow()
+	_, opener, _ := b.allow()
+	if !b.record(fault, opener) {
+		t.Fatalf("expected the fault to open the breaker")
+	}
+
+	now = now.Add(10 * time.Second)
+	_, trial, _ := b.allow()
+	if !trial {
+		t.Fatalf("expected a trial run after the cooldown")
+	}
+	// The runs that started before the breaker opened finish first.
+	if b.record(nil, lateSuccess) {
+		t.Fatalf("a late success must not open the breaker")
+	}
+	if ok, _, _ := b.allow(); ok {
+		t.Fatalf("a run admitted before the breaker opened must not close it")
+	}
+	if b.record(fault, lateFault) {
+		t.Fatalf("a late fault must not reopen the breaker")
+	}
+	if ok, _, _ := b.allow(); ok {
+		t.Fatalf("expected the trial to still be the only run let through")
+	}
+
+	b.record(nil, trial)
+	if ok, _, _ := b.allow(); !ok {
+		t.Fatalf("expected the trial's success to close the breaker")
+	}
+}
+
+func TestCircuitBreakerForgetsFaultsOutsideWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(2, time.Minute, time.Second)
+	b.now = func() time.Time { return now }
+	fault := newAPIError(500, codeEngineCrashed, "engine crashed")
+
+	b.record(fault, false)
+	now = now.Add(2 * time.Minute)
+	if b.record(fault, false) {
+		t.Fatalf("faults a window apart must not open the breaker")
+	}
+}
+
+func TestRepeatedEngineFailuresOpenTheBreaker(t *testing.T) {
+	t.Setenv("BREAKER_THRESHOLD", "2")
+	t.Setenv("BREAKER_COOLDOWN", "20ms")
+	projectRoot := newTestProject(t, "mesh.obj", 4)
+	failing := true
+	calls := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			calls++
+			if failing {
+				return []byte("segfault in loader"), errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+		readFile: func(string) ([]byte, error) { return []byte(`{"path":[0,1]}`), nil },
+	})
+	compute := func() int {
+		return performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`).Code
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := compute(); code != http.StatusInternalServerError {
+			t.Fatalf("request %d: expected status 500, got %d", i, code)
+		}
+	}
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After while open, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp := decodeJSONBody(t, w); resp["code"] != string(codeCircuitOpen) {
+		t.Fatalf("expected code %s, got %v", codeCircuitOpen, resp["code"])
+	}
+	if calls != 2 {
+		t.Fatalf("expected the open breaker to skip the engine, got %d runs", calls)
+	}
+
+	failing = false
+	time.Sleep(30 * time.Millisecond)
+	if code := compute(); code != http.StatusOK {
+		t.Fatalf("expected the trial run to succeed, got %d", code)
+	}
+	if code := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":2,"model":"mesh.obj"}`).Code; code != http.StatusOK {
+		t.Fatalf("expected the breaker to be closed after a success, got %d", code)
+	}
+}
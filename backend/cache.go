@@ -0,0 +1,172 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCacheSize = 256
+
+// resultCache is a bounded LRU of engine results. A capacity of zero disables
+// caching. With a ttl, entries older than it are misses even if their model
+// looks unchanged, for storage that can replace a file without touching its
+// mtime.
+type resultCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	entries  map[string]*list.Element
+	now      func() time.Time
+
+	// hits, misses and evictions count get and put outcomes since startup
+	// for GET /cache/stats and /metrics.
+	hits, misses, evictions atomic.Int64
+}
+
+// cacheStats is the GET /cache/stats response. hitRate is hits over all
+// lookups, or 0 before the first one.
+type cacheStats struct {
+	Size      int     `json:"size"`
+	Capacity  int     `json:"capacity"`
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	HitRate   float64 `json:"hitRate"`
+}
+
+type cacheEntry struct {
+	key    string
+	res    engineResult
+	stored time.Time
+}
+
+func newResultCache(capacity int, ttl time.Duration) *resultCache {
+	return &resultCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// resultCacheKey identifies a computation. The model's modification time is
+// part of the key so that editing a model invalidates its cached results.
+func resultCacheKey(modelModTime time.Time, args []string) string {
+	h := sha256.New()
+	h.Write([]byte(modelModTime.UTC().Format(time.RFC3339Nano)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(args, "\x00")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (rc *resultCache) get(key string) (engineResult, bool) {
+	res, ok := rc.lookup(key)
+	if ok {
+		rc.hits.Add(1)
+	} else {
+		rc.misses.Add(1)
+	}
+	return res, ok
+}
+
+// lookup is get without counting a hit or miss, for rechecking a key whose
+// miss has already been counted.
+func (rc *resultCache) lookup(key string) (engineResult, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return engineResult{}, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if rc.ttl > 0 && rc.now().Sub(entry.stored) >= rc.ttl {
+		rc.order.Remove(elem)
+		delete(rc.entries, key)
+		return engineResult{}, false
+	}
+	rc.order.MoveToFront(elem)
+	return entry.res, true
+}
+
+// enabled reports whether put keeps anything; CACHE_SIZE=0 disables caching.
+func (rc *resultCache) enabled() bool { return rc.capacity > 0 }
+
+// put stores res, keeping when and how long it was computed so that a hit
+// can still report them in ?meta=true.
+func (rc *resultCache) put(key string, res engineResult) {
+	if rc.capacity <= 0 {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := rc.now()
+	if elem, ok := rc.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.res, entry.stored = res, now
+		rc.order.MoveToFront(elem)
+		return
+	}
+
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, res: res, stored: now})
+	for rc.order.Len() > rc.capacity {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+		rc.evictions.Add(1)
+	}
+}
+
+// flush drops every entry. The counters keep running; flushed entries are
+// not counted as evictions.
+func (rc *resultCache) flush() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	n := rc.order.Len()
+	rc.order.Init()
+	clear(rc.entries)
+	return n
+}
+
+func (rc *resultCache) stats() cacheStats {
+	st := cacheStats{
+		Size:      rc.len(),
+		Capacity:  rc.capacity,
+		Hits:      rc.hits.Load(),
+		Misses:    rc.misses.Load(),
+		Evictions: rc.evictions.Load(),
+	}
+	if lookups := st.Hits + st.Misses; lookups > 0 {
+		st.HitRate = float64(st.Hits) / float64(lookups)
+	}
+	return st
+}
+
+func (rc *resultCache) len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.order.Len()
+}
+
+func (s *server) handleCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, s.cache.stats())
+}
+
+// handleFlushCache empties the result cache. Like every DELETE it needs the
+// API key when API_KEY is set.
+func (s *server) handleFlushCache(c *gin.Context) {
+	n := s.cache.flush()
+	s.deps.logger.Info("result cache flushed", "request_id", requestIDFrom(c), "entries", n)
+	c.JSON(http.StatusOK, gin.H{"flushed": n})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	rc := newResultCache(2, 0)
+	rc.put("a", engineResult{payload: []byte("A")})
+	rc.put("b", engineResult{payload: []byte("B")})
+	if _, ok := rc.get("a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	rc.put("c", engineResult{payload: []byte("C")})
+
+	if _, ok := rc.get("b"); ok {
+		t.Fatalf("expected b to be evicted as least recently used")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := rc.get(key); !ok {
+			t.Fatalf("expected %s to remain cached", key)
+		}
+	}
+	if rc.len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", rc.len())
+	}
+}
+
+func TestResultCacheZeroCapacityDisablesCaching(t *testing.T) {
+	rc := newResultCache(0, 0)
+	rc.put("a", engineResult{payload: []byte("A")})
+	if _, ok := rc.get("a"); ok {
+		t.Fatalf("expected nothing to be cached with zero capacity")
+	}
+}
+
+func newCountingRouter(t *testing.T, projectRoot string, runs *int) http.Handler {
+	t.Helper()
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			*runs++
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+}
+
+func TestIdenticalComputeIsServedFromCache(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newCountingRouter(t, projectRoot, &runs)
+
+	body := `{"start":1,"end":2,"model":"mesh.obj"}`
+	for i, want := range []cacheStatus{cacheMiss, cacheHit} {
+		w := performRequest(router, http.MethodPost, "/compute", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d", i, w.Code)
+		}
+		if w.Body.String() != `{"path":[],"curves":[]}` {
+			t.Fatalf("request %d: unexpected payload %s", i, w.Body.String())
+		}
+		if got := w.Header().Get(cacheStatusHeader); got != string(want) {
+			t.Fatalf("request %d: expected %s %s, got %q", i, cacheStatusHeader, want, got)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("expected engine to run once, ran %d times", runs)
+	}
+
+	// A different mode or endpoint pair is a different computation.
+	performRequest(router, http.MethodPost, "/heat", body)
+	performRequest(router, http.MethodPost, "/compute", `{"start":2,"end":1,"model":"mesh.obj"}`)
+	if runs != 3 {
+		t.Fatalf("expected distinct requests to miss the cache, engine ran %d times", runs)
+	}
+}
+
+func TestModifiedModelBustsCache(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newCountingRouter(t, projectRoot, &runs)
+
+	body := `{"start":1,"end":2,"model":"mesh.obj"}`
+	performRequest(router, http.MethodPost, "/compute", body)
+
+	modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(modelPath, later, later); err != nil {
+		t.Fatalf("failed to touch model: %v", err)
+	}
+
+	performRequest(router, http.MethodPost, "/compute", body)
+	if runs != 2 {
+		t.Fatalf("expected modified model to re-run the engine, ran %d times", runs)
+	}
+}
+
+func TestCacheSizeZeroAlwaysRunsEngine(t *testing.T) {
+	t.Setenv("CACHE_SIZE", "0")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newCountingRouter(t, projectRoot, &runs)
+
+	body := `{"start":1,"end":2,"model":"mesh.obj"}`
+	performRequest(router, http.MethodPost, "/compute", body)
+	performRequest(router, http.MethodPost, "/compute", body)
+	if runs != 2 {
+		t.Fatalf("expected engine to run for every request, ran %d times", runs)
+	}
+}
+
+func TestResultCacheEntriesExpireAfterTTL(t *testing.T) {
+	rc := newResultCache(2, time.Minute)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rc.now = func() time.Time { return now }
+
+	rc.put("a", engineResult{payload: []byte("A")})
+	now = now.Add(time.Minute - time.Second)
+	if _, ok := rc.get("a"); !ok {
+		t.Fatalf("expected a to be cached within the TTL")
+	}
+	rc.put("b", engineResult{payload: []byte("B")})
+	now = now.Add(time.Second)
+	if _, ok := rc.get("a"); ok {
+		t.Fatalf("expected a to expire once the TTL has passed")
+	}
+	if _, ok := rc.get("b"); !ok {
+		t.Fatalf("expected b, stored later, to be cached still")
+	}
+	if st := rc.stats(); st.Size != 1 || st.Misses != 1 || st.Evictions != 0 {
+		t.Fatalf("expected the expired entry to be dropped as a miss, got %+v", st)
+	}
+}
+
+func TestCacheTTLRecomputesUnchangedModel(t *testing.T) {
+	t.Setenv("CACHE_TTL", "10m")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	s := newServer(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			runs++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[]}`), nil },
+	})
+	now := time.Now()
+	s.cache.now = func() time.Time { return now }
+	router := s.router()
+
+	body := `{"start":1,"end":2,"model":"mesh.obj"}`
+	for i, step := range []struct {
+		advance time.Duration
+		want    cacheStatus
+	}{
+		{want: cacheMiss},
+		{advance: 9 * time.Minute, want: cacheHit},
+		{advance: time.Minute, want: cacheMiss},
+	} {
+		now = now.Add(step.advance)
+		w := performRequest(router, http.MethodPost, "/compute", body)
+		if got := w.Header().Get(cacheStatusHeader); got != string(step.want) {
+			t.Fatalf("request %d: expected %s %s, got %q", i, cacheStatusHeader, step.want, got)
+		}
+	}
+	if runs != 2 {
+		t.Fatalf("expected the expired result to be recomputed, engine ran %d times", runs)
+	}
+}
+
+func TestResultCacheCountsEvictions(t *testing.T) {
+	cache := newResultCache(1, 0)
+	cache.put("a", engineResult{payload: []byte("1")})
+	cache.put("b", engineResult{payload: []byte("2")})
+	cache.get("a")
+	cache.get("b")
+	st := cache.stats()
+	if st.Size != 1 || st.Hits != 1 || st.Misses != 1 || st.Evictions != 1 || st.HitRate != 0.5 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestCacheStatsAndFlush(t *testing.T) {
+	t.Setenv("CACHE_SIZE", "8")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newCountingRouter(t, projectRoot, &runs)
+
+	for _, body := range []string{
+		`{"start":1,"end":2,"model":"mesh.obj"}`,
+		`{"start":1,"end":2,"model":"mesh.obj"}`,
+		`{"start":1,"end":3,"model":"mesh.obj"}`,
+		`{"start":1,"end":2,"model":"mesh.obj"}`,
+	} {
+		if w := performRequest(router, http.MethodPost, "/compute", body); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+	}
+	stats := decodeJSONBody(t, performRequest(router, http.MethodGet, "/cache/stats", ""))
+	want := map[string]any{"size": 2.0, "capacity": 8.0, "hits": 2.0, "misses": 2.0, "evictions": 0.0, "hitRate": 0.5}
+	for key, value := range want {
+		if stats[key] != value {
+			t.Fatalf("expected %s=%v, got %v in %v", key, value, stats[key], stats)
+		}
+	}
+
+	w := performRequest(router, http.MethodDelete, "/cache", "")
+	if w.Code != http.StatusOK || decodeJSONBody(t, w)["flushed"] != 2.0 {
+		t.Fatalf("expected both entries flushed, got %d %s", w.Code, w.Body.String())
+	}
+	if stats := decodeJSONBody(t, performRequest(router, http.MethodGet, "/cache/stats", "")); stats["size"] != 0.0 {
+		t.Fatalf("expected an empty cache after the flush, got %v", stats)
+	}
+	performRequest(router, http.MethodPost, "/compute", `{"start":1,"end":2,"model":"mesh.obj"}`)
+	if runs != 3 {
+		t.Fatalf("expected the flushed result to be recomputed, engine ran %d times", runs)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// engineOutputPrefix names the output directories created under the system
+// temp directory: one per mode, holding a directory per run that is named
+// with engineRunPrefix.
+const (
+	engineOutputPrefix = "geodesic-engine-"
+	engineRunPrefix    = "run-"
+)
+
+// modeOutputDir is the directory under base that holds mode's runs, so the
+// result files of different modes never share a directory.
+func modeOutputDir(base, mode string) string {
+	return filepath.Join(base, engineOutputPrefix+mode)
+}
+
+// staleOutputAge is how old an output directory must be before the startup
+// sweep treats it as abandoned. It is far longer than any engine run, so the
+// sweep never races a live request from another backend sharing the temp dir.
+const staleOutputAge = time.Hour
+
+// sweepStaleOutputDirs removes engine output directories under tmpDir last
+// modified before cutoff. They are left behind only when the backend itself
+// was killed mid-run, since execute removes its directory on every path.
+// Stale runs inside a mode directory are removed first; the mode directory
+// goes too once it is itself stale.
+func sweepStaleOutputDirs(tmpDir string, cutoff time.Time) (removed int, err error) {
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), engineOutputPrefix) {
+			continue
+		}
+		dir := filepath.Join(tmpDir, entry.Name())
+		removed += sweepDirs(dir, engineRunPrefix, cutoff)
+		info, err := os.Stat(dir)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if os.RemoveAll(dir) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// sweepDirs removes the directories in dir named with prefix and last
+// modified before cutoff.
+func sweepDirs(dir, prefix string, cutoff time.Time) (removed int) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if os.RemoveAll(filepath.Join(dir, entry.Name())) == nil {
+			removed++
+		}
+	}
+	return removed
+}
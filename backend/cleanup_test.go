@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFailedRunLeavesNoOutputBehind(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	fakeEngineCommand(t, "partial-fail")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	if leftovers, _ := os.ReadDir(modeOutputDir(tmpDir, "dijkstra")); len(leftovers) != 0 {
+		t.Fatalf("expected the failed run's output directory to be removed, found %d entries", len(leftovers))
+	}
+}
+
+func TestSweepStaleOutputDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	mkdir := func(name string, age time.Duration) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, "result.json"), []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	stale := mkdir(engineOutputPrefix+"old", 2*staleOutputAge)
+	fresh := mkdir(engineOutputPrefix+"new", time.Minute)
+	unrelated := mkdir("other-old", 2*staleOutputAge)
+
+	removed, err := sweepStaleOutputDirs(tmpDir, now.Add(-staleOutputAge))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected one directory removed, got %d", removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected stale output directory to be removed")
+	}
+	for _, keep := range []string{fresh, unrelated} {
+		if _, err := os.Stat(keep); err != nil {
+			t.Fatalf("expected %s to be kept: %v", keep, err)
+		}
+	}
+}
+
+func TestSweepStaleRunsInsideModeDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	now := time.Now()
+	modeDir := modeOutputDir(tmpDir, "heat")
+	stale := filepath.Join(modeDir, engineRunPrefix+"old")
+	fresh := filepath.Join(modeDir, engineRunPrefix+"new")
+	for _, dir := range []string{stale, fresh} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(stale, now.Add(-2*staleOutputAge), now.Add(-2*staleOutputAge)); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := sweepStaleOutputDirs(tmpDir, now.Add(-staleOutputAge))
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one stale run removed, got %d (%v)", removed, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale run to be removed")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Fatalf("expected the fresh run and its mode dir to be kept: %v", err)
+	}
+}
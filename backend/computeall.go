@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// computeAllModes are the sections of a /compute_all response, each keyed by
+// its batchModes name.
+var computeAllModes = []string{"dijkstra", "analytics", "heat"}
+
+// handleComputeAll runs Dijkstra, analytics and heat for one start/end pair
+// concurrently so a dashboard needs a single request. Input problems shared
+// by every section are rejected up front; engine failures are reported in
+// their own section.
+func (s *server) handleComputeAll(c *gin.Context) {
+	req, err := bindComputeJSON(c)
+	if err != nil {
+		respondError(c, errRequestBody(err))
+		return
+	}
+	c.Set(ctxComputeRequest, req)
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+	requestID := requestIDFrom(c)
+	if _, err := s.buildJob(c.Request.Context(), requestID, projectRoot, forRoute(req, batchModes["dijkstra"]), batchModes["dijkstra"]); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	outcomes := make([]outcome, len(computeAllModes))
+	s.forEachConcurrently(len(computeAllModes), func(i int) {
+		route := batchModes[computeAllModes[i]]
+		outcomes[i] = s.computeOutcome(c.Request.Context(), requestID, projectRoot, forRoute(req, route), route)
+	})
+
+	results := make(map[string]outcome, len(computeAllModes))
+	for i, mode := range computeAllModes {
+		results[mode] = outcomes[i]
+	}
+	body, err := json.Marshal(results)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	writePayload(c, http.StatusOK, body)
+}
+
+// forRoute clears the flags route does not read, so a /compute_all request
+// applies weighted to the analytics section and directed to Dijkstra alone.
+func forRoute(req computeRequest, route engineRoute) computeRequest {
+	if !route.weighted {
+		req.Weighted = false
+	}
+	if !route.directed {
+		req.Directed = false
+	}
+	return req
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newComputeAllRouter(t *testing.T, failMode string) http.Handler {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			if failMode != "" && slices.Contains(args, failMode) {
+				return []byte("Error: analytic surface not recognised"), errors.New("exit status 2")
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			if strings.HasSuffix(path, "result.json") && !strings.HasSuffix(path, "heat_result.json") {
+				return []byte(`{"path":[0,4]}`), nil
+			}
+			return []byte(`{"curves":[]}`), nil
+		},
+	})
+}
+
+func decodeComputeAll(t *testing.T, body []byte) map[string]outcome {
+	t.Helper()
+	var results map[string]outcome
+	if err := json.Unmarshal(body, &results); err != nil {
+		t.Fatalf("failed to decode results: %v (%s)", err, body)
+	}
+	return results
+}
+
+func TestComputeAllReturnsEverySection(t *testing.T) {
+	router := newComputeAllRouter(t, "")
+	w := performRequest(router, http.MethodPost, "/compute_all", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	results := decodeComputeAll(t, w.Body.Bytes())
+	want := map[string]string{
+		"dijkstra":  `{"path":[0,4]}`,
+		"analytics": `{"curves":[]}`,
+		"heat":      `{"curves":[]}`,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d sections, got %v", len(want), results)
+	}
+	for section, result := range want {
+		got := results[section]
+		if got.Status != http.StatusOK || string(got.Result) != result {
+			t.Fatalf("%s: expected 200 %s, got %d %s", section, result, got.Status, got.Result)
+		}
+	}
+}
+
+func TestComputeAllReportsFailuresPerSection(t *testing.T) {
+	router := newComputeAllRouter(t, "analytics")
+	w := performRequest(router, http.MethodPost, "/compute_all", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	results := decodeComputeAll(t, w.Body.Bytes())
+	if got := results["analytics"]; got.Status != http.StatusInternalServerError || got.Error == nil || got.Error.Code != codeEngineFailed {
+		t.Fatalf("expected analytics to fail with ENGINE_FAILED, got %+v", got)
+	}
+	for _, section := range []string{"dijkstra", "heat"} {
+		if got := results[section]; got.Status != http.StatusOK || got.Error != nil {
+			t.Fatalf("expected %s to succeed, got %+v", section, got)
+		}
+	}
+}
+
+func TestComputeAllRejectsInvalidInputOnce(t *testing.T) {
+	router := newComputeAllRouter(t, "")
+	w := performRequest(router, http.MethodPost, "/compute_all", `{"start":0,"end":40,"model":"mesh.obj"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// newFlagRecordingRouter serves /compute_all and records, per section,
+// whether the engine was passed flag.
+func newFlagRecordingRouter(t *testing.T, flag string) (http.Handler, map[string]bool) {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var mu sync.Mutex
+	passed := map[string]bool{}
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, args ...string) ([]byte, error) {
+			mode := "dijkstra"
+			if len(args) > 3 && !strings.HasPrefix(args[3], "--") {
+				mode = args[3]
+			}
+			mu.Lock()
+			passed[mode] = slices.Contains(args, flag)
+			mu.Unlock()
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			if strings.HasSuffix(path, "result.json") && !strings.HasSuffix(path, "heat_result.json") {
+				return []byte(`{"path":[0,4]}`), nil
+			}
+			return []byte(`{"curves":[]}`), nil
+		},
+	})
+	return router, passed
+}
+
+func TestComputeAllAppliesFlagsToTheSectionsThatReadThem(t *testing.T) {
+	cases := []struct {
+		field string
+		flag  string
+		want  map[string]bool
+	}{
+		{field: "weighted", flag: weightedFlag, want: map[string]bool{"dijkstra": false, "analytics": true, "heat": false}},
+		{field: "directed", flag: directedFlag, want: map[string]bool{"dijkstra": true, "analytics": false, "heat": false}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.field, func(t *testing.T) {
+			router, passed := newFlagRecordingRouter(t, tc.flag)
+			body := fmt.Sprintf(`{"start":0,"end":4,"model":"mesh.obj",%q:true}`, tc.field)
+			w := performRequest(router, http.MethodPost, "/compute_all", body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			for section, got := range decodeComputeAll(t, w.Body.Bytes()) {
+				if got.Status != http.StatusOK {
+					t.Fatalf("%s: expected 200, got %+v", section, got)
+				}
+			}
+			if !maps.Equal(passed, tc.want) {
+				t.Fatalf("expected %s only where it is read, got %v", tc.flag, passed)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPort           = "8080"
+	defaultEngineTimeout  = 30 * time.Second
+	defaultSlotWait       = 2 * time.Second
+	defaultEngineRetries  = 2
+	defaultRequestTimeout = 60 * time.Second
+	defaultQueueFactor    = 4
+
+	// maxEngineNice is the lowest priority Unix schedulers offer.
+	maxEngineNice = 19
+)
+
+// config holds the runtime settings read from the environment at startup.
+type config struct {
+	listenAddr     string
+	engineTimeout  time.Duration
+	cacheSize      int
+	shutdownGrace  time.Duration
+	enginePath     string
+	dataDir        string
+	maxConcurrency int
+	slotWait       time.Duration
+	maxUploadBytes int64
+	allowedOrigins []string
+	maxBodyBytes   int64
+	batchBodyBytes int64
+	historyFile    string
+	rateLimit      int
+	rateBurst      int
+	logFormat      string
+	engineRetries  int
+	apiKey         string
+	// outputDir, when set, is the engine's working directory and the parent
+	// of its per-run output directories instead of the project root and the
+	// system temp dir.
+	outputDir string
+	// modelFetchTimeout bounds the download of a model given by URL.
+	modelFetchTimeout time.Duration
+	// modelFetchHosts are the hosts models may be downloaded from; empty
+	// disables models given by URL. modelFetchPrivate also allows hosts that
+	// resolve to loopback, private or link-local addresses.
+	modelFetchHosts   map[string]bool
+	modelFetchPrivate bool
+	// maxModelBytes rejects larger models before the engine loads them; 0
+	// means no limit.
+	maxModelBytes int64
+	// requestTimeout bounds each request as a whole, engine run included.
+	requestTimeout time.Duration
+	// corsMaxAge and corsAllowHeaders shape the CORS preflight response.
+	corsMaxAge       time.Duration
+	corsAllowHeaders []string
+	// breakerThreshold engine faults within breakerWindow open the circuit
+	// breaker for breakerCooldown; a threshold of 0 disables it.
+	breakerThreshold int
+	breakerWindow    time.Duration
+	breakerCooldown  time.Duration
+	// engines maps the names requests may select to engine paths, which are
+	// resolved like ENGINE_PATH.
+	engines map[string]string
+	// enablePprof mounts the runtime profiler under pprofPrefix.
+	enablePprof bool
+	// maxQueue is how many requests may wait for one of the maxConcurrency
+	// engine slots; it defaults to defaultQueueFactor times maxConcurrency.
+	maxQueue int
+	// debug adds server-side paths to error responses.
+	debug bool
+	// idempotencyTTL is how long a response is replayed to retries that
+	// repeat its Idempotency-Key.
+	idempotencyTTL time.Duration
+	// modeDefaultsFile holds per-mode parameter defaults; see modeParams.
+	modeDefaultsFile string
+	// engineNice lowers the engine's scheduling priority; 0 leaves it alone.
+	engineNice int
+	// maxVertexIndex caps start and end whatever the model's vertex count.
+	maxVertexIndex int
+	// cacheTTL expires cached results; 0 keeps them until evicted.
+	cacheTTL time.Duration
+}
+
+func loadConfig() config {
+	cfg := config{
+		listenAddr:        resolveListenAddr(os.Getenv("ADDR"), os.Getenv("HOST"), os.Getenv("PORT")),
+		engineTimeout:     envDuration("ENGINE_TIMEOUT", defaultEngineTimeout),
+		cacheSize:         envInt("CACHE_SIZE", defaultCacheSize),
+		shutdownGrace:     envDuration("SHUTDOWN_GRACE", defaultShutdownGrace),
+		enginePath:        strings.TrimSpace(os.Getenv("ENGINE_PATH")),
+		engines:           parseEngines(os.Getenv("ENGINES")),
+		enablePprof:       envBool("ENABLE_PPROF"),
+		debug:             envBool("DEBUG"),
+		idempotencyTTL:    envDuration("IDEMPOTENCY_TTL", defaultIdempotencyTTL),
+		dataDir:           strings.TrimSpace(os.Getenv("DATA_DIR")),
+		maxConcurrency:    envInt("MAX_CONCURRENCY", runtime.NumCPU()),
+		slotWait:          envDuration("ENGINE_SLOT_WAIT", defaultSlotWait),
+		maxUploadBytes:    int64(envInt("MAX_UPLOAD_BYTES", defaultMaxUploadBytes)),
+		allowedOrigins:    parseAllowedOrigins(os.Getenv("ALLOWED_ORIGINS")),
+		maxBodyBytes:      int64(envInt("MAX_BODY_BYTES", defaultMaxBodyBytes)),
+		batchBodyBytes:    int64(envInt("MAX_BATCH_BODY_BYTES", defaultMaxBatchBodyBytes)),
+		historyFile:       strings.TrimSpace(os.Getenv("HISTORY_FILE")),
+		rateLimit:         envInt("RATE_LIMIT", defaultRateLimit),
+		rateBurst:         envInt("RATE_BURST", defaultRateBurst),
+		logFormat:         parseLogFormat(os.Getenv("LOG_FORMAT")),
+		engineRetries:     envInt("ENGINE_RETRIES", defaultEngineRetries),
+		apiKey:            strings.TrimSpace(os.Getenv("API_KEY")),
+		outputDir:         envPath("ENGINE_OUTPUT_DIR"),
+		modelFetchTimeout: envDuration("MODEL_FETCH_TIMEOUT", defaultModelFetchTimeout),
+		modelFetchHosts:   parseHostList(os.Getenv("MODEL_FETCH_HOSTS")),
+		modelFetchPrivate: envBool("MODEL_FETCH_ALLOW_PRIVATE"),
+		maxModelBytes:     int64(envInt("MAX_MODEL_BYTES", 0)),
+		requestTimeout:    envDuration("REQUEST_TIMEOUT", defaultRequestTimeout),
+		corsMaxAge:        envDuration("CORS_MAX_AGE", defaultCORSMaxAge),
+		corsAllowHeaders:  parseAllowHeaders(os.Getenv("CORS_ALLOW_HEADERS")),
+		breakerThreshold:  envInt("BREAKER_THRESHOLD", defaultBreakerThreshold),
+		breakerWindow:     envDuration("BREAKER_WINDOW", defaultBreakerWindow),
+		breakerCooldown:   envDuration("BREAKER_COOLDOWN", defaultBreakerCooldown),
+		cacheTTL:          envDuration("CACHE_TTL", 0),
+	}
+	if cfg.maxConcurrency == 0 {
+		cfg.maxConcurrency = runtime.NumCPU()
+	}
+	cfg.maxQueue = envInt("MAX_QUEUE", defaultQueueFactor*cfg.maxConcurrency)
+	if cfg.engineNice = envInt("ENGINE_NICE", 0); cfg.engineNice > maxEngineNice {
+		log.Printf("clamping ENGINE_NICE=%d to %d", cfg.engineNice, maxEngineNice)
+		cfg.engineNice = maxEngineNice
+	}
+	if cfg.maxVertexIndex = envInt("MAX_VERTEX_INDEX", defaultMaxVertexIndex); cfg.maxVertexIndex == 0 {
+		cfg.maxVertexIndex = defaultMaxVertexIndex
+	}
+	cfg.modeDefaultsFile = strings.TrimSpace(os.Getenv("MODE_DEFAULTS_FILE"))
+	if cfg.modeDefaultsFile == "" {
+		cfg.modeDefaultsFile = defaultModeDefaultsFile
+	}
+	return cfg
+}
+
+// resolveListenAddr returns ADDR when set, else HOST:PORT. An empty HOST
+// listens on all interfaces and PORT defaults to 8080.
+func resolveListenAddr(addr, host, port string) string {
+	if addr = strings.TrimSpace(addr); addr != "" {
+		return addr
+	}
+	port = strings.TrimSpace(port)
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(strings.TrimSpace(host), port)
+}
+
+// envInt parses a non-negative integer, falling back to the default when the
+// variable is missing or malformed.
+func envInt(key string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		log.Printf("ignoring invalid %s=%q, using %d", key, raw, fallback)
+		return fallback
+	}
+	return n
+}
+
+// envBool parses a boolean flag, which is off when missing or malformed.
+func envBool(key string) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q, using false", key, raw)
+		return false
+	}
+	return b
+}
+
+// envPath reads a directory path, making it absolute so it does not depend
+// on the working directory of the engine subprocess.
+func envPath(key string) string {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(raw)
+	if err != nil {
+		log.Printf("ignoring invalid %s=%q: %v", key, raw, err)
+		return ""
+	}
+	return abs
+}
+
+// envDuration parses a Go duration ("45s", "2m") or a bare number of seconds.
+// Missing, malformed, or non-positive values fall back to the default.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(raw); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if d, err = time.ParseDuration(raw); err != nil {
+		d = 0
+	}
+	if d <= 0 {
+		log.Printf("ignoring invalid %s=%q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
+
+// parseHostList reads a comma-separated list of host names, lowercased.
+func parseHostList(raw string) map[string]bool {
+	hosts := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		if host := strings.ToLower(strings.TrimSpace(part)); host != "" {
+			hosts[host] = true
+		}
+	}
+	return hosts
+}
+
+// parseEngines reads ENGINES, a comma-separated list of name=path pairs.
+// Malformed entries are dropped.
+func parseEngines(raw string) map[string]string {
+	engines := map[string]string{}
+	for _, part := range strings.Split(raw, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, path, ok := strings.Cut(part, "=")
+		name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			log.Printf("ignoring invalid ENGINES entry %q", strings.TrimSpace(part))
+			continue
+		}
+		engines[name] = path
+	}
+	return engines
+}
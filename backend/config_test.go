@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnvDurationParsing(t *testing.T) {
+	cases := []struct {
+		raw      string
+		expected time.Duration
+	}{
+		{raw: "", expected: defaultEngineTimeout},
+		{raw: "45s", expected: 45 * time.Second},
+		{raw: "2m", expected: 2 * time.Minute},
+		{raw: "10", expected: 10 * time.Second},
+		{raw: "soon", expected: defaultEngineTimeout},
+		{raw: "-5s", expected: defaultEngineTimeout},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			t.Setenv("ENGINE_TIMEOUT", tc.raw)
+			if got := envDuration("ENGINE_TIMEOUT", defaultEngineTimeout); got != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveListenAddr(t *testing.T) {
+	cases := []struct {
+		name     string
+		addr     string
+		host     string
+		port     string
+		expected string
+	}{
+		{name: "defaults", expected: ":8080"},
+		{name: "port only", port: "9000", expected: ":9000"},
+		{name: "host only", host: "127.0.0.1", expected: "127.0.0.1:8080"},
+		{name: "host and port", host: "127.0.0.1", port: "9000", expected: "127.0.0.1:9000"},
+		{name: "ipv6 host", host: "::1", port: "9000", expected: "[::1]:9000"},
+		{name: "addr wins", addr: "10.0.0.5:7000", host: "127.0.0.1", port: "9000", expected: "10.0.0.5:7000"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveListenAddr(tc.addr, tc.host, tc.port); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
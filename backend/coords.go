@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Coordinate-based requests report the vertices they resolved to in these
+// response headers.
+const (
+	resolvedStartHeader = "X-Resolved-Start"
+	resolvedEndHeader   = "X-Resolved-End"
+)
+
+// coordinate is a point picked on the rendered model, in degrees.
+type coordinate struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// readModelVertices returns the x, y, z position of every "v" line in an OBJ.
+func readModelVertices(modelPath string) ([][3]float64, error) {
+	f, err := os.Open(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var vertices [][3]float64
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) < 4 || string(fields[0]) != "v" {
+			continue
+		}
+		var v [3]float64
+		for i := range v {
+			if v[i], err = strconv.ParseFloat(string(fields[i+1]), 64); err != nil {
+				return nil, fmt.Errorf("vertex %d: %w", len(vertices), err)
+			}
+		}
+		vertices = append(vertices, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vertices, nil
+}
+
+// nearestVertex maps a lat/lng onto the model by treating it as a direction
+// from the model's centroid (y up, lng 0 along +x) and returning the vertex
+// whose own direction is closest to it.
+func nearestVertex(vertices [][3]float64, coord coordinate) int {
+	var centroid [3]float64
+	for _, v := range vertices {
+		for i := range centroid {
+			centroid[i] += v[i] / float64(len(vertices))
+		}
+	}
+
+	lat := coord.Lat * math.Pi / 180
+	lng := coord.Lng * math.Pi / 180
+	dir := [3]float64{math.Cos(lat) * math.Cos(lng), math.Sin(lat), -math.Cos(lat) * math.Sin(lng)}
+
+	best, bestScore := 0, math.Inf(-1)
+	for idx, v := range vertices {
+		d := [3]float64{v[0] - centroid[0], v[1] - centroid[1], v[2] - centroid[2]}
+		norm := math.Sqrt(d[0]*d[0] + d[1]*d[1] + d[2]*d[2])
+		if norm == 0 {
+			continue
+		}
+		if score := (d[0]*dir[0] + d[1]*dir[1] + d[2]*dir[2]) / norm; score > bestScore {
+			best, bestScore = idx, score
+		}
+	}
+	return best
+}
+
+func validateCoordinate(field string, coord coordinate) *validationError {
+	if coord.Lat < -90 || coord.Lat > 90 || coord.Lng < -180 || coord.Lng > 180 {
+		return &validationError{
+			field:   field,
+			message: fmt.Sprintf("%s must have lat in [-90, 90] and lng in [-180, 180], got (%g, %g)", field, coord.Lat, coord.Lng),
+			details: map[string]any{"field": field, "lat": coord.Lat, "lng": coord.Lng},
+		}
+	}
+	return nil
+}
+
+// resolveCoordinates sets Start/End to the nearest vertex for whichever
+// of startCoord/endCoord the client sent.
+func resolveCoordinates(req computeRequest, modelName, modelPath string) (computeRequest, error) {
+	if req.StartCoord == nil && req.EndCoord == nil {
+		return req, nil
+	}
+	if req.StartCoord != nil {
+		if vErr := validateCoordinate("startCoord", *req.StartCoord); vErr != nil {
+			return req, vErr
+		}
+	}
+	if req.EndCoord != nil {
+		if vErr := validateCoordinate("endCoord", *req.EndCoord); vErr != nil {
+			return req, vErr
+		}
+	}
+	if _, err := statModel(modelName, modelPath); err != nil {
+		return req, err
+	}
+
+	vertices, err := readModelVertices(modelPath)
+	if err != nil {
+		return req, fmt.Errorf("failed to read model vertices: %w", err)
+	}
+	if len(vertices) == 0 {
+		return req, &validationError{field: "startCoord", message: "model has no vertices"}
+	}
+	if req.StartCoord != nil {
+		start := nearestVertex(vertices, *req.StartCoord)
+		req.Start = &start
+	}
+	if req.EndCoord != nil {
+		end := nearestVertex(vertices, *req.EndCoord)
+		req.End = &end
+	}
+	return req, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newOctahedronProject writes a model with one vertex on each axis:
+// +x, -x, +y, -y, +z, -z.
+func newOctahedronProject(t *testing.T) string {
+	t.Helper()
+	root := newTestProject(t, "placeholder.obj", 1)
+	obj := "v 1 0 0\nv -1 0 0\nv 0 1 0\nv 0 -1 0\nv 0 0 1\nv 0 0 -1\nf 1 3 5\n"
+	if err := os.WriteFile(filepath.Join(root, "frontend", "public", "data", "octa.obj"), []byte(obj), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	return root
+}
+
+func newCoordRouter(t *testing.T, got *engineCall) http.Handler {
+	t.Helper()
+	projectRoot := newOctahedronProject(t)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+}
+
+func TestNearestVertex(t *testing.T) {
+	vertices := [][3]float64{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+	cases := []struct {
+		coord    coordinate
+		expected int
+	}{
+		{coord: coordinate{Lat: 0, Lng: 0}, expected: 0},
+		{coord: coordinate{Lat: 0, Lng: 180}, expected: 1},
+		{coord: coordinate{Lat: 80, Lng: 30}, expected: 2},
+		{coord: coordinate{Lat: -90, Lng: 0}, expected: 3},
+		{coord: coordinate{Lat: 10, Lng: -90}, expected: 4},
+		{coord: coordinate{Lat: 0, Lng: 85}, expected: 5},
+	}
+	for _, tc := range cases {
+		if got := nearestVertex(vertices, tc.coord); got != tc.expected {
+			t.Fatalf("nearestVertex(%+v) = %d, want %d", tc.coord, got, tc.expected)
+		}
+	}
+}
+
+func TestComputeResolvesCoordinatesToVertices(t *testing.T) {
+	var got engineCall
+	router := newCoordRouter(t, &got)
+
+	w := performRequest(router, http.MethodPost, "/compute",
+		`{"model":"octa.obj","startCoord":{"lat":90,"lng":0},"endCoord":{"lat":0,"lng":180}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if got.args[0] != "2" || got.args[1] != "1" {
+		t.Fatalf("expected resolved vertices 2 and 1, got %v", got.args)
+	}
+	if w.Header().Get(resolvedStartHeader) != "2" || w.Header().Get(resolvedEndHeader) != "1" {
+		t.Fatalf("expected resolved indices in headers, got %q/%q",
+			w.Header().Get(resolvedStartHeader), w.Header().Get(resolvedEndHeader))
+	}
+}
+
+func TestComputeMixesCoordinateAndIndexEndpoints(t *testing.T) {
+	var got engineCall
+	router := newCoordRouter(t, &got)
+
+	w := performRequest(router, http.MethodPost, "/compute",
+		`{"model":"octa.obj","start":4,"endCoord":{"lat":-90,"lng":0}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if got.args[0] != "4" || got.args[1] != "3" {
+		t.Fatalf("expected index start 4 and resolved end 3, got %v", got.args)
+	}
+}
+
+func TestComputeIndexRequestsHaveNoResolvedHeaders(t *testing.T) {
+	var got engineCall
+	router := newCoordRouter(t, &got)
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"model":"octa.obj","start":0,"end":5}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got.args[0] != "0" || got.args[1] != "5" {
+		t.Fatalf("expected indices to pass through, got %v", got.args)
+	}
+	if h := w.Header().Get(resolvedStartHeader); h != "" {
+		t.Fatalf("expected no resolved header for index requests, got %q", h)
+	}
+}
+
+func TestComputeRejectsOutOfRangeCoordinates(t *testing.T) {
+	var got engineCall
+	router := newCoordRouter(t, &got)
+
+	w := performRequest(router, http.MethodPost, "/compute",
+		`{"model":"octa.obj","startCoord":{"lat":91,"lng":0},"end":1}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", w.Code)
+	}
+	if details := errorDetails(t, decodeJSONBody(t, w)); details["field"] != "startCoord" {
+		t.Fatalf("expected startCoord to be reported, got %v", details)
+	}
+	if got.args != nil {
+		t.Fatalf("expected the engine not to run")
+	}
+}
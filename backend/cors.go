@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultCORSMaxAge = 12 * time.Hour
+
+// defaultAllowHeaders is used when CORS_ALLOW_HEADERS is unset.
+var defaultAllowHeaders = []string{"Content-Type"}
+
+// parseAllowHeaders splits CORS_ALLOW_HEADERS on commas, falling back to
+// defaultAllowHeaders when it names no headers.
+func parseAllowHeaders(raw string) []string {
+	var headers []string
+	for _, part := range strings.Split(raw, ",") {
+		if header := strings.TrimSpace(part); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	if len(headers) == 0 {
+		return defaultAllowHeaders
+	}
+	return headers
+}
+
+// parseAllowedOrigins splits ALLOWED_ORIGINS on commas. Entries that are not
+// http(s) origins are dropped, since the CORS middleware rejects them.
+func parseAllowedOrigins(raw string) []string {
+	var origins []string
+	for _, part := range strings.Split(raw, ",") {
+		origin := strings.TrimRight(strings.TrimSpace(part), "/")
+		if origin == "" {
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			log.Printf("ignoring invalid ALLOWED_ORIGINS entry %q", origin)
+			continue
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// isLocalOrigin reports whether origin points at this machine, on any port.
+func isLocalOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// allowHeaders returns CORS_ALLOW_HEADERS plus the request headers the
+// server itself reads: X-Request-ID and Idempotency-Key always, and X-API-Key
+// when a key is required.
+func (s *server) allowHeaders() []string {
+	headers := slices.Clone(s.cfg.corsAllowHeaders)
+	required := []string{requestIDHeader, idempotencyKeyHeader}
+	if s.cfg.apiKey != "" {
+		required = append(required, apiKeyHeader)
+	}
+	for _, header := range required {
+		if !slices.ContainsFunc(headers, func(h string) bool { return strings.EqualFold(h, header) }) {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// originAllowed reports whether origin is listed in ALLOWED_ORIGINS, or is
+// local (the Vite dev server) when the list is empty.
+func (s *server) originAllowed(origin string) bool {
+	if len(s.cfg.allowedOrigins) == 0 {
+		return isLocalOrigin(origin)
+	}
+	return slices.ContainsFunc(s.cfg.allowedOrigins, func(o string) bool { return strings.EqualFold(o, origin) })
+}
+
+// corsMiddleware allows the origins originAllowed accepts. Preflights
+// advertise the methods routed on the requested path, so a browser learns
+// that /compute_batch takes only POST and /cache/stats only GET.
+func (s *server) corsMiddleware(r *gin.Engine) gin.HandlerFunc {
+	handler := cors.New(cors.Config{
+		AllowOriginFunc: s.originAllowed,
+		AllowHeaders:    s.allowHeaders(),
+		// Let browser clients read the headers that describe the result.
+		ExposeHeaders: []string{requestIDHeader, resolvedStartHeader, resolvedEndHeader, "Deprecation", "Link", "ETag", totalCountHeader, engineDurationHeader, "Content-Disposition", "Warning", idempotentReplayedHeader, cacheStatusHeader},
+		MaxAge:        s.cfg.corsMaxAge,
+	})
+	return func(c *gin.Context) {
+		if isPreflight(c.Request) && s.originAllowed(c.GetHeader("Origin")) {
+			if methods := allowedMethods(r.Routes(), c.Request.URL.Path); len(methods) > 0 {
+				c.Header("Access-Control-Allow-Methods", strings.Join(append(methods, http.MethodOptions), ","))
+			}
+		}
+		handler(c)
+	}
+}
+
+// isPreflight reports whether req is a CORS preflight rather than a plain
+// OPTIONS request.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func performPreflight(t *testing.T, origin string) *httptest.ResponseRecorder {
+	t.Helper()
+	return performPreflightTo(t, newTestRouter(appDeps{}), "/compute", origin)
+}
+
+func performPreflightTo(t *testing.T, router http.Handler, path, origin string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSAllowlist(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://geodesic.example.com, https://lab.example.com/")
+
+	cases := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://geodesic.example.com", allowed: true},
+		{origin: "https://lab.example.com", allowed: true},
+		{origin: "https://evil.example.com", allowed: false},
+		{origin: "http://localhost:5173", allowed: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.origin, func(t *testing.T) {
+			w := performPreflight(t, tc.origin)
+			got := w.Header().Get("Access-Control-Allow-Origin")
+			if tc.allowed && got != tc.origin {
+				t.Fatalf("expected origin %q to be allowed, got header %q", tc.origin, got)
+			}
+			if !tc.allowed && got != "" {
+				t.Fatalf("expected origin %q to be rejected, got header %q", tc.origin, got)
+			}
+		})
+	}
+}
+
+func TestCORSDefaultsToLocalhost(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "")
+
+	for _, origin := range []string{"http://localhost:5173", "http://127.0.0.1:8080"} {
+		if got := performPreflight(t, origin).Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Fatalf("expected local origin %q to be allowed, got %q", origin, got)
+		}
+	}
+	w := performPreflight(t, "https://geodesic.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("expected remote origin to be rejected without ALLOWED_ORIGINS, got %q", got)
+	}
+}
+
+func TestCORSPreflightReflectsConfiguredHeadersAndMaxAge(t *testing.T) {
+	t.Setenv("CORS_MAX_AGE", "10m")
+	t.Setenv("CORS_ALLOW_HEADERS", "Content-Type, Authorization")
+	t.Setenv("API_KEY", "secret")
+
+	w := performPreflight(t, "http://localhost:5173")
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Fatalf("expected max age 600, got %q", got)
+	}
+	got := strings.Split(w.Header().Get("Access-Control-Allow-Headers"), ",")
+	want := []string{"Content-Type", "Authorization", requestIDHeader, idempotencyKeyHeader, apiKeyHeader}
+	if !slices.EqualFunc(got, want, strings.EqualFold) {
+		t.Fatalf("expected allowed headers %v, got %v", want, got)
+	}
+}
+
+func TestCORSPreflightAdvertisesTheRouteMethods(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "")
+	router := newTestRouter(appDeps{})
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "/v1/compute", want: "GET,POST,OPTIONS"},
+		{path: "/v1/compute_batch", want: "POST,OPTIONS"},
+		{path: "/cache/stats", want: "GET,OPTIONS"},
+		{path: "/cache", want: "DELETE,OPTIONS"},
+		{path: "/v1/models", want: "GET,POST,OPTIONS"},
+		{path: "/v1/models/mesh.obj", want: "DELETE,OPTIONS"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			w := performPreflightTo(t, router, tc.path, "http://localhost:5173")
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("expected status 204, got %d", w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != tc.want {
+				t.Fatalf("expected methods %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if got := performPreflightTo(t, router, "/v1/compute", "https://evil.example.com").Header().Get("Access-Control-Allow-Methods"); got != "" {
+		t.Fatalf("expected no methods for a rejected origin, got %q", got)
+	}
+}
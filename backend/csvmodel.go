@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvModelExt marks edge-list models that are converted to OBJ before the
+// engine sees them.
+const csvModelExt = ".csv"
+
+// isCSVModel reports whether name is an edge-list model, compressed or not.
+func isCSVModel(name string) bool {
+	return strings.EqualFold(filepath.Ext(trimGzipExt(name)), csvModelExt)
+}
+
+// convertedModelPath is where the OBJ conversion of a CSV model is cached:
+// a hidden file next to the original, so GET /models does not list it. The
+// name differs from the earlier face-based conversion, so those copies are
+// never reused.
+func convertedModelPath(csvPath string) string {
+	return filepath.Join(filepath.Dir(csvPath), "."+filepath.Base(csvPath)+".edges.obj")
+}
+
+// engineModelPath returns the file the engine should load for a model: gzip
+// models are decompressed first, up to limit bytes, then CSV models
+// converted to OBJ.
+func engineModelPath(name, path string, limit int64) (string, error) {
+	path, err := decompressModel(name, path, limit)
+	if err != nil {
+		return "", err
+	}
+	return convertModel(name, path)
+}
+
+// convertModel returns path for OBJ models. CSV models are converted on first
+// use and again whenever the CSV is newer than its cached conversion.
+func convertModel(name, path string) (string, error) {
+	if !isCSVModel(name) {
+		return path, nil
+	}
+	info, err := statModel(name, path)
+	if err != nil {
+		return "", err
+	}
+	converted := convertedModelPath(path)
+	if cached, err := os.Stat(converted); err == nil && !cached.ModTime().Before(info.ModTime()) {
+		return converted, nil
+	}
+	if err := convertCSVModel(path, converted); err != nil {
+		return "", err
+	}
+	return converted, nil
+}
+
+// checkSurfaceModel rejects edge-list models for modes that work on the
+// mesh surface, such as heat and analytics: a converted CSV has no faces.
+func checkSurfaceModel(name, mode string) *validationError {
+	if !isCSVModel(name) {
+		return nil
+	}
+	return &validationError{
+		field:   "model",
+		message: fmt.Sprintf("%s mode needs a triangle mesh; edge-list models have no faces", mode),
+		details: map[string]any{"model": name, "mode": mode},
+	}
+}
+
+// csvEdge is one source,target,weight row of an edge-list model.
+type csvEdge struct {
+	source, target int
+	weight         float64
+}
+
+// readCSVEdges parses an edge list of non-negative integer vertex IDs and
+// non-negative weights. A leading header row is skipped. Malformed rows are
+// reported with their line number.
+func readCSVEdges(r io.Reader) ([]csvEdge, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var edges []csvEdge
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return edges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if row == 0 && isCSVHeader(record) {
+			continue
+		}
+		edge, err := parseCSVEdge(record)
+		if err != nil {
+			return nil, &validationError{
+				field:   "model",
+				message: fmt.Sprintf("line %d: %v", line, err),
+				details: map[string]any{"line": line},
+			}
+		}
+		edges = append(edges, edge)
+	}
+}
+
+func isCSVHeader(record []string) bool {
+	return len(record) >= 2 &&
+		strings.EqualFold(strings.TrimSpace(record[0]), "source") &&
+		strings.EqualFold(strings.TrimSpace(record[1]), "target")
+}
+
+func parseCSVEdge(record []string) (csvEdge, error) {
+	if len(record) != 3 {
+		return csvEdge{}, fmt.Errorf("expected source,target,weight, got %d fields", len(record))
+	}
+	var edge csvEdge
+	var err error
+	if edge.source, err = parseCSVVertex("source", record[0]); err != nil {
+		return csvEdge{}, err
+	}
+	if edge.target, err = parseCSVVertex("target", record[1]); err != nil {
+		return csvEdge{}, err
+	}
+	weight := strings.TrimSpace(record[2])
+	edge.weight, err = strconv.ParseFloat(weight, 64)
+	if err != nil || edge.weight < 0 || math.IsInf(edge.weight, 0) || math.IsNaN(edge.weight) {
+		return csvEdge{}, fmt.Errorf("weight must be a non-negative number, got %q", weight)
+	}
+	return edge, nil
+}
+
+func parseCSVVertex(field, raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer vertex ID, got %q", field, raw)
+	}
+	return n, nil
+}
+
+// convertCSVModel writes the OBJ form of an edge list to dst. Vertex IDs
+// become vertex indices, so a graph with IDs up to N has N+1 vertices, and
+// each row becomes an "e source target weight" line, which the engine reads
+// as an edge of exactly that weight. The result has no faces, so surface
+// modes cannot use it; see checkSurfaceModel.
+func convertCSVModel(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	edges, err := readCSVEdges(in)
+	if err != nil {
+		return err
+	}
+
+	var obj strings.Builder
+	fmt.Fprintf(&obj, "# converted from %s\n", filepath.Base(src))
+	nodes := 0
+	for _, e := range edges {
+		nodes = max(nodes, e.source+1, e.target+1)
+	}
+	for i := 0; i < nodes; i++ {
+		fmt.Fprintf(&obj, "v %d 0 0\n", i)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&obj, "e %d %d %s\n", e.source+1, e.target+1, strconv.FormatFloat(e.weight, 'g', -1, 64))
+	}
+
+	// Write then rename so concurrent requests never load a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(obj.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
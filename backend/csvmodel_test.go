@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCSVProject writes graph.csv into a fresh project and returns the router
+// and the engine arguments of the last run.
+func newCSVProject(t *testing.T, csv string) (http.Handler, string, *[]string) {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 1)
+	csvPath := filepath.Join(projectRoot, "frontend", "public", "data", "graph.csv")
+	if err := os.WriteFile(csvPath, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var lastArgs []string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			lastArgs, _ = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,2]}`), nil },
+	})
+	return router, csvPath, &lastArgs
+}
+
+func TestCSVModelIsConvertedForTheEngine(t *testing.T) {
+	router, csvPath, lastArgs := newCSVProject(t, "source,target,weight\n0,1,2.5\n1,2,1\n")
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":2,"model":"graph.csv"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	converted := convertedModelPath(csvPath)
+	if len(*lastArgs) < 3 || (*lastArgs)[2] != converted {
+		t.Fatalf("expected the engine to load %s, got %v", converted, *lastArgs)
+	}
+	obj, err := os.ReadFile(converted)
+	if err != nil {
+		t.Fatalf("expected a converted model: %v", err)
+	}
+	for _, want := range []string{"v 0 0 0\nv 1 0 0\nv 2 0 0\n", "e 1 2 2.5\n", "e 2 3 1\n"} {
+		if !strings.Contains(string(obj), want) {
+			t.Fatalf("expected converted model to contain %q, got:\n%s", want, obj)
+		}
+	}
+}
+
+func TestCSVModelReportsBadRowLine(t *testing.T) {
+	router, _, _ := newCSVProject(t, "source,target,weight\n0,1,2.5\n1,two,1\n")
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"graph.csv"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if msg, _ := body["message"].(string); !strings.HasPrefix(msg, "line 3: target must be") {
+		t.Fatalf("expected the bad row's line in the message, got %q", msg)
+	}
+	if details := errorDetails(t, body); details["line"] != float64(3) {
+		t.Fatalf("expected line 3 in details, got %v", details)
+	}
+}
+
+func TestCSVModelConversionIsCached(t *testing.T) {
+	router, csvPath, _ := newCSVProject(t, "0,1,1\n1,2,1\n")
+	body := `{"start":0,"end":2,"model":"graph.csv"}`
+	if w := performRequest(router, http.MethodPost, "/compute", body); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Date the CSV back and give the conversion a fixed, newer time; a second
+	// conversion would overwrite that time.
+	converted := convertedModelPath(csvPath)
+	csvTime := time.Now().Add(-2 * time.Hour)
+	convertedTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(csvPath, csvTime, csvTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(converted, convertedTime, convertedTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":2,"end":0,"model":"graph.csv"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	info, err := os.Stat(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(convertedTime) {
+		t.Fatalf("expected the cached conversion to be reused, mtime changed to %s", info.ModTime())
+	}
+}
+
+func TestCSVModelIsRejectedBySurfaceModes(t *testing.T) {
+	router, _, lastArgs := newCSVProject(t, "0,1,1\n1,2,1\n")
+
+	for _, route := range []string{"/heat", "/analytics"} {
+		w := performRequest(router, http.MethodPost, route, `{"start":0,"end":2,"model":"graph.csv"}`)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected status 422, got %d: %s", route, w.Code, w.Body.String())
+		}
+	}
+	if len(*lastArgs) != 0 {
+		t.Fatalf("expected the engine not to run, got %v", *lastArgs)
+	}
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":2,"model":"graph.csv"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected path modes to accept the edge list, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCSVModelInfoCountsItsEdges(t *testing.T) {
+	router, _, _ := newCSVProject(t, "0,1,1\n1,2,1\n2,0,4\n")
+
+	w := performRequest(router, http.MethodGet, "/models/graph.csv/info", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if body["nodes"] != float64(3) || body["edges"] != float64(3) {
+		t.Fatalf("expected 3 nodes and 3 edges, got %v", body)
+	}
+}
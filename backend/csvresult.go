@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const csvMediaType = "text/csv"
+
+// csvFallbackWarning is sent in a Warning header when a client asked for CSV
+// but the result has no tabular form.
+const csvFallbackWarning = `299 - "CSV is not available for this result; returned JSON"`
+
+// acceptsCSV reports whether an Accept header asks for text/csv with a
+// non-zero quality. JSON stays the default for every other header.
+func acceptsCSV(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != csvMediaType {
+			continue
+		}
+		q, err := strconv.ParseFloat(params["q"], 64)
+		return err != nil || q > 0
+	}
+	return false
+}
+
+// resultCSV flattens the known result shapes into CSV: a path becomes one
+// step,vertex row per vertex and curves one curve,point,x,y,z row per point.
+// ok is false for any other shape.
+func resultCSV(payload []byte) (out []byte, ok bool) {
+	var result struct {
+		Path   []int `json:"path"`
+		Curves []struct {
+			Name   string       `json:"name"`
+			Points [][3]float64 `json:"points"`
+		} `json:"curves"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, false
+	}
+
+	var rows [][]string
+	switch {
+	case result.Path != nil:
+		rows = append(rows, []string{"step", "vertex"})
+		for i, v := range result.Path {
+			rows = append(rows, []string{strconv.Itoa(i), strconv.Itoa(v)})
+		}
+	case result.Curves != nil:
+		rows = append(rows, []string{"curve", "point", "x", "y", "z"})
+		for _, curve := range result.Curves {
+			for i, p := range curve.Points {
+				rows = append(rows, []string{curve.Name, strconv.Itoa(i),
+					formatCoord(p[0]), formatCoord(p[1]), formatCoord(p[2])})
+			}
+		}
+	default:
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+func formatCoord(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// negotiateCSV converts payload to CSV when the client asked for it. It
+// returns the body and content type to send; results without a CSV form are
+// sent as JSON with csvFallbackWarning.
+func negotiateCSV(c *gin.Context, payload []byte, resultFileName string) ([]byte, string) {
+	c.Writer.Header().Add("Vary", "Accept")
+	if !acceptsCSV(c.GetHeader("Accept")) {
+		return payload, "application/json"
+	}
+	out, ok := resultCSV(payload)
+	if !ok {
+		c.Header("Warning", csvFallbackWarning)
+		return payload, "application/json"
+	}
+	name := strings.TrimSuffix(resultFileName, ".json") + ".csv"
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	return out, csvMediaType + "; charset=utf-8"
+}
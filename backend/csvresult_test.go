@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func performWithAccept(router http.Handler, path, body, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newCSVRouter(t *testing.T, result string) http.Handler {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(result), nil },
+	})
+}
+
+func TestPathResultAsCSV(t *testing.T) {
+	router := newCSVRouter(t, `{"path":[0,2,6,9],"totalDistance":3.1}`)
+	body := `{"start":0,"end":9,"model":"mesh.obj"}`
+
+	w := performWithAccept(router, "/compute", body, "text/csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("expected a CSV content type, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != "attachment; filename=result.csv" {
+		t.Fatalf("unexpected Content-Disposition %q", cd)
+	}
+	if want := "step,vertex\n0,0\n1,2\n2,6\n3,9\n"; w.Body.String() != want {
+		t.Fatalf("expected CSV %q, got %q", want, w.Body.String())
+	}
+
+	w = performWithAccept(router, "/compute", body, "application/json")
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" || !strings.HasPrefix(w.Body.String(), "{") {
+		t.Fatalf("expected JSON by default, got %q %s", ct, w.Body.String())
+	}
+}
+
+func TestCurvesResultAsCSV(t *testing.T) {
+	router := newCSVRouter(t, `{"curves":[{"name":"heat_geodesic","length":1,"points":[[0,-1,0],[0.5,0.25,1]]}]}`)
+	w := performWithAccept(router, "/analytics", `{"start":0,"end":9,"model":"mesh.obj"}`, "text/csv;q=0.9, application/json;q=0.5")
+	if want := "curve,point,x,y,z\nheat_geodesic,0,0,-1,0\nheat_geodesic,1,0.5,0.25,1\n"; w.Body.String() != want {
+		t.Fatalf("expected CSV %q, got %q", want, w.Body.String())
+	}
+}
+
+func TestUnsupportedCSVShapeFallsBackToJSON(t *testing.T) {
+	router := newCSVRouter(t, `[{"path":[0,1]},{"path":[0,2,1]}]`)
+	w := performWithAccept(router, "/compute_ksp", `{"start":0,"end":1,"model":"mesh.obj","k":2}`, "text/csv")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected a JSON fallback, got %q", ct)
+	}
+	if w.Header().Get("Warning") != csvFallbackWarning {
+		t.Fatalf("expected a Warning header, got %q", w.Header().Get("Warning"))
+	}
+	if w.Body.String() != `[{"path":[0,1]},{"path":[0,2,1]}]` {
+		t.Fatalf("expected the JSON result, got %s", w.Body.String())
+	}
+}
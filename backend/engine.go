@@ -0,0 +1,700 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// engineJob describes one invocation of the C++ engine and the result file it
+// is expected to write into its output directory.
+type engineJob struct {
+	requestID      string
+	mode           string
+	start, end     int
+	projectRoot    string
+	model          string
+	modelPath      string
+	args           []string
+	resultFileName string
+	// resultKeys and arrayResult describe a well-formed result; see
+	// checkResultShape.
+	resultKeys  []string
+	arrayResult bool
+	// onProgress, when set, runs the engine in streaming mode and is called
+	// for every "PROGRESS <n>" line it prints.
+	onProgress func(percent int)
+	// checkResult, when set, can reject a successful run based on its payload.
+	checkResult func(payload []byte) error
+	// sameNodePath answers start == end without running the engine.
+	sameNodePath bool
+	// engine is the ENGINES entry to run, or "" for the default binary.
+	engine string
+}
+
+// engineRunError is returned when the engine exits unsuccessfully. signal is
+// set when the engine was killed by a signal rather than exiting.
+type engineRunError struct {
+	output string
+	signal string
+	err    error
+}
+
+func (e *engineRunError) Error() string {
+	if msg := strings.TrimSpace(e.output); msg != "" {
+		return msg
+	}
+	if e.signal != "" {
+		return "engine terminated by " + e.signal
+	}
+	return e.err.Error()
+}
+
+func (e *engineRunError) Unwrap() error { return e.err }
+
+// engineTimeoutError is returned when the engine run (or the result read that
+// follows it) does not finish within the configured ENGINE_TIMEOUT.
+type engineTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *engineTimeoutError) Error() string {
+	return fmt.Sprintf("engine timed out after %s", e.timeout)
+}
+
+// resultReadError is returned when the engine succeeded but its result file
+// could not be read back.
+type resultReadError struct {
+	fileName string
+	err      error
+}
+
+func (e *resultReadError) Error() string { return "failed to read " + e.fileName }
+
+func (e *resultReadError) Unwrap() error { return e.err }
+
+// resolveEnginePath returns the engine binary location: ENGINE_PATH when set
+// (relative values are taken from the project root), else <root>/main.
+func resolveEnginePath(override, projectRoot string) string {
+	if override == "" {
+		return filepath.Join(projectRoot, "main")
+	}
+	if filepath.IsAbs(override) {
+		return filepath.Clean(override)
+	}
+	return filepath.Join(projectRoot, override)
+}
+
+// engineOverride returns the configured path of the named engine, or
+// ENGINE_PATH for the default one.
+func (s *server) engineOverride(name string) string {
+	if name == "" {
+		return s.cfg.enginePath
+	}
+	return s.cfg.engines[name]
+}
+
+// checkEngineExecutable reports why path cannot be run as the engine, if so.
+func checkEngineExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
+
+// outputDirFlag tells the engine where to write its result file. Every run
+// gets a fresh directory so concurrent requests cannot overwrite each other.
+const outputDirFlag = "--output-dir"
+
+// directedFlag asks the path modes to follow edges only in the order their
+// face or edge-list line gives them. Without it every edge is undirected.
+const directedFlag = "--directed"
+
+// weightedFlag asks the analytics mode to weight edges by their length (or
+// an edge-list model's weight column). Without it every edge counts as one
+// hop.
+const weightedFlag = "--weighted"
+
+// noPathWarning is sent in a Warning header with a path result whose end is
+// unreachable from its start. The engine still exits 0 and writes
+// "reachable": false with an empty path, so the body is served as is, and
+// the header tells it apart from a crash at a glance.
+const noPathWarning = `299 - "no path between start and end"`
+
+// unreachableResult reports whether a path result says its end cannot be
+// reached from its start.
+func unreachableResult(payload []byte) bool {
+	var result struct {
+		Reachable *bool `json:"reachable"`
+	}
+	return json.Unmarshal(payload, &result) == nil && result.Reachable != nil && !*result.Reachable
+}
+
+// sameNodeResult is the path from a vertex to itself, which path modes
+// answer without running the engine, in the shape the engine writes.
+func sameNodeResult(vertex int) []byte {
+	return fmt.Appendf(nil, `{"path":[%d],"totalDistance":0,"reachable":true}`, vertex)
+}
+
+// execCommand builds engine subprocesses; tests swap it for a fake process.
+var execCommand = exec.CommandContext
+
+func defaultRunEngine(ctx context.Context, workDir, enginePath string, args ...string) ([]byte, error) {
+	cmd := execCommand(ctx, enginePath, args...)
+	cmd.Dir = workDir
+	configureProcessGroup(cmd)
+	// Don't let a stray grandchild holding the output pipe keep us waiting
+	// once the engine itself has been killed.
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	nice := engineNiceFrom(ctx)
+	niced := niceCommand(cmd, nice)
+	err := cmd.Start()
+	if err == nil {
+		if !niced {
+			lowerPriority(cmd, nice)
+		}
+		err = cmd.Wait()
+	}
+	if sink, ok := ctx.Value(stdoutSinkKey{}).(func([]byte)); ok {
+		sink(stdout.Bytes())
+	}
+	return diagnosticOutput(stdout.Bytes(), stderr.Bytes()), err
+}
+
+type stdoutSinkKey struct{}
+
+type engineNiceKey struct{}
+
+// withEngineNice asks the default runners to start the engine with its
+// scheduling priority lowered by nice (ENGINE_NICE).
+func withEngineNice(ctx context.Context, nice int) context.Context {
+	return context.WithValue(ctx, engineNiceKey{}, nice)
+}
+
+func engineNiceFrom(ctx context.Context) int {
+	nice, _ := ctx.Value(engineNiceKey{}).(int)
+	return nice
+}
+
+// withStdoutSink asks defaultRunEngine to hand the engine's complete stdout
+// to sink once the run ends, whatever it returns as diagnostic output.
+func withStdoutSink(ctx context.Context, sink func(stdout []byte)) context.Context {
+	return context.WithValue(ctx, stdoutSinkKey{}, sink)
+}
+
+// captureStdout collects the engine's stdout for logEngineStdout in DEBUG
+// mode, from either runner; otherwise it returns ctx and onLine unchanged.
+func (s *server) captureStdout(ctx context.Context, onLine func(string)) (context.Context, func(string), *bytes.Buffer) {
+	if !s.cfg.debug {
+		return ctx, onLine, nil
+	}
+	var stdout bytes.Buffer
+	ctx = withStdoutSink(ctx, func(b []byte) { stdout.Write(b) })
+	return ctx, func(line string) {
+		stdout.WriteString(line + "\n")
+		onLine(line)
+	}, &stdout
+}
+
+// logEngineStdout logs what the engine printed to stdout, typically its
+// progress, so slow runs can be diagnosed from the server side.
+func (s *server) logEngineStdout(job engineJob, stdout *bytes.Buffer) {
+	if stdout == nil || len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return
+	}
+	s.deps.logger.Info("engine stdout",
+		"request_id", job.requestID,
+		"stdout", strings.TrimSpace(stdout.String()))
+}
+
+// diagnosticOutput picks the stream that best explains a run: stderr when the
+// engine wrote anything there, otherwise stdout (where it prints progress and
+// some solver errors).
+func diagnosticOutput(stdout, stderr []byte) []byte {
+	if len(bytes.TrimSpace(stderr)) > 0 {
+		return stderr
+	}
+	return stdout
+}
+
+// engineResult is a successful run's result file and how long the engine
+// subprocess took to produce it, not counting the result read. A cached
+// result keeps the engineTime and computedAt of the run that produced it.
+type engineResult struct {
+	payload    []byte
+	engineTime time.Duration
+	// computedAt is when the engine finished; zero when no engine ran.
+	computedAt time.Time
+	// cache is where the result came from, reported as cacheStatusHeader;
+	// empty when it needed neither the cache nor the engine.
+	cache cacheStatus
+}
+
+// runEngine executes the job and returns the contents of its result file. The
+// engine timeout bounds both the subprocess and the result read. Successful
+// results are cached per model version and argument list and recorded in the
+// history log. Failures are returned as *apiError carrying the matching error
+// code.
+func (s *server) runEngine(parent context.Context, job engineJob) (res engineResult, err error) {
+	parent, span := s.tracer.Start(parent, "runEngine", trace.WithAttributes(jobAttributes(job)...))
+	defer func() { endSpan(span, err) }()
+
+	began := time.Now()
+	cacheKey, err := jobCacheKey(job)
+	if err != nil {
+		return engineResult{}, err
+	}
+	if job.sameNodePath && job.start == job.end {
+		res.payload = sameNodeResult(job.start)
+		s.recordHistory(job, time.Since(began))
+		return res, nil
+	}
+	res, hit := s.cache.get(cacheKey)
+	span.SetAttributes(attribute.Bool("geodesic.cache_hit", hit))
+	if hit {
+		res.cache = cacheHit
+	} else if res, err = s.computeOnce(parent, cacheKey, job); err != nil {
+		return engineResult{}, err
+	}
+
+	if job.checkResult != nil {
+		if err := job.checkResult(res.payload); err != nil {
+			return engineResult{}, err
+		}
+	}
+	s.recordHistory(job, time.Since(began))
+	return res, nil
+}
+
+// jobCacheKey returns the result cache key of job, which changes whenever its
+// model file is modified.
+func jobCacheKey(job engineJob) (string, error) {
+	info, err := statModel(job.model, job.modelPath)
+	if err != nil {
+		return "", err
+	}
+	args := job.args
+	if job.engine != "" {
+		args = append(slices.Clip(args), "engine="+job.engine)
+	}
+	return resultCacheKey(info.ModTime(), args), nil
+}
+
+// computeOnce runs job and caches its result, sharing one engine run among
+// concurrent callers with the same cache key. Each caller gets its own copy of
+// the payload; failures are returned to every waiter but never cached.
+// Streaming jobs always run on their own so progress reaches their client.
+//
+// A caller whose context ends stops waiting straight away, but the shared run
+// is only cancelled once every caller has gone.
+func (s *server) computeOnce(parent context.Context, cacheKey string, job engineJob) (engineResult, error) {
+	run := func(ctx context.Context) (engineResult, error) {
+		// Another run may have filled the key since runEngine's miss.
+		if res, ok := s.cache.lookup(cacheKey); ok {
+			res.cache = cacheHit
+			return res, nil
+		}
+		res, err := s.executeWithRetry(ctx, job)
+		if err != nil {
+			return engineResult{}, err
+		}
+		s.cache.put(cacheKey, res)
+		res.cache = cacheMiss
+		return res, nil
+	}
+	if job.onProgress != nil {
+		return run(parent)
+	}
+
+	shared := s.joinRun(parent, cacheKey)
+	defer s.leaveRun(cacheKey, shared)
+	// Only the caller whose function singleflight runs sees ran set; the
+	// results channel orders the write before the read below.
+	ran := false
+	results := s.flights.DoChan(cacheKey, func() (any, error) {
+		ran = true
+		return run(shared.ctx)
+	})
+	select {
+	case res := <-results:
+		if res.Err != nil {
+			return engineResult{}, res.Err
+		}
+		shared := res.Val.(engineResult)
+		shared.payload = bytes.Clone(shared.payload)
+		if !ran && shared.cache == cacheMiss {
+			shared.cache = cacheHitShared
+		}
+		return shared, nil
+	case <-parent.Done():
+		return engineResult{}, errCanceled(parent.Err())
+	}
+}
+
+// sharedRun is the context of one deduplicated engine run and the number of
+// callers still waiting on it.
+type sharedRun struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+func (s *server) joinRun(parent context.Context, key string) *sharedRun {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	shared := s.runs[key]
+	if shared == nil {
+		// Detached from parent so the first caller leaving does not cancel
+		// the run for everyone else; leaveRun cancels it instead.
+		ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+		shared = &sharedRun{ctx: ctx, cancel: cancel}
+		s.runs[key] = shared
+	}
+	shared.waiters++
+	return shared
+}
+
+func (s *server) leaveRun(key string, shared *sharedRun) {
+	s.runsMu.Lock()
+	defer s.runsMu.Unlock()
+	shared.waiters--
+	if shared.waiters > 0 {
+		return
+	}
+	shared.cancel()
+	delete(s.runs, key)
+	// A caller arriving after this point must start a fresh run rather than
+	// join the cancelled one.
+	s.flights.Forget(key)
+}
+
+// engineRetryBackoff is the pause before the first retry; later retries wait
+// proportionally longer.
+const engineRetryBackoff = 100 * time.Millisecond
+
+// executeWithRetry runs job, re-running up to ENGINE_RETRIES times when the
+// engine succeeded but its result file could not be read, and once more if
+// the file it read was corrupt. Engine exits and timeouts are never retried.
+//
+// While the circuit breaker is open the engine is not started at all.
+func (s *server) executeWithRetry(parent context.Context, job engineJob) (engineResult, error) {
+	if s.breaker == nil {
+		return s.executeAttempts(parent, job)
+	}
+	ok, trial, wait := s.breaker.allow()
+	if !ok {
+		return engineResult{}, newAPIError(503, codeCircuitOpen,
+			"engine is failing repeatedly, retry later").withRetryAfter(wait)
+	}
+	res, err := s.executeAttempts(parent, job)
+	if s.breaker.record(err, trial) {
+		s.deps.logger.Error("engine circuit breaker opened",
+			"request_id", job.requestID,
+			"cooldown", s.cfg.breakerCooldown.String(),
+			"error", err.Error())
+	}
+	return res, err
+}
+
+// executeAttempts recomputes a corrupt result once, since a truncated write
+// rarely repeats. execute has already deleted the bad file, and corrupt
+// results never reach the cache, which is only filled after the shape check.
+func (s *server) executeAttempts(parent context.Context, job engineJob) (engineResult, error) {
+	res, err := s.executeReads(parent, job)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.code == codeResultCorrupt && parent.Err() == nil {
+		s.deps.logger.Warn("recomputing corrupt engine result",
+			"request_id", job.requestID,
+			"error", apiErr.message)
+		res, err = s.executeReads(parent, job)
+	}
+	return res, err
+}
+
+// executeReads retries job while its result file cannot be read.
+func (s *server) executeReads(parent context.Context, job engineJob) (engineResult, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := s.execute(parent, job)
+		var readErr *resultReadError
+		if err == nil || attempt > s.cfg.engineRetries || !errors.As(err, &readErr) {
+			return res, err
+		}
+		s.deps.logger.Warn("retrying engine run",
+			"request_id", job.requestID,
+			"attempt", attempt,
+			"error", readErr.err.Error())
+
+		timer := time.NewTimer(time.Duration(attempt) * engineRetryBackoff)
+		select {
+		case <-timer.C:
+		case <-parent.Done():
+			timer.Stop()
+			return engineResult{}, err
+		}
+	}
+}
+
+// withModelPath adds the resolved model path to apiErr in DEBUG mode only,
+// since it reveals the server's filesystem layout.
+func (s *server) withModelPath(apiErr *apiError, job engineJob) *apiError {
+	if s.cfg.debug {
+		apiErr.withDetail("modelPath", job.modelPath)
+	}
+	return apiErr
+}
+
+// redactModelPath replaces the resolved model path in engine output with the
+// model name the client sent, unless in DEBUG mode.
+func (s *server) redactModelPath(output string, job engineJob) string {
+	if s.cfg.debug || job.modelPath == "" {
+		return output
+	}
+	return strings.ReplaceAll(output, job.modelPath, job.model)
+}
+
+// engineWorkDir is the directory the engine runs in: ENGINE_OUTPUT_DIR when
+// set, so nothing the engine writes lands in the frontend's public assets.
+func (s *server) engineWorkDir(job engineJob) string {
+	if s.cfg.outputDir != "" {
+		return s.cfg.outputDir
+	}
+	return job.projectRoot
+}
+
+// engineOutputBase is the parent of the per-run output directories.
+func (s *server) engineOutputBase() string {
+	if s.cfg.outputDir != "" {
+		return s.cfg.outputDir
+	}
+	return os.TempDir()
+}
+
+// execute runs the engine subprocess for job in its own output directory and
+// reads back the result file.
+func (s *server) execute(parent context.Context, job engineJob) (engineResult, error) {
+	release, err := s.acquireEngineSlot(parent)
+	if err != nil {
+		return engineResult{}, err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(parent, s.cfg.engineTimeout)
+	defer cancel()
+	// Shutdown kills engines that outlive the grace period.
+	stop := context.AfterFunc(s.engines.ctx, cancel)
+	defer stop()
+
+	modeDir := modeOutputDir(s.engineOutputBase(), job.mode)
+	if err := os.MkdirAll(modeDir, 0o755); err != nil {
+		return engineResult{}, toAPIError(err)
+	}
+	outputDir, err := os.MkdirTemp(modeDir, engineRunPrefix+"*")
+	if err != nil {
+		return engineResult{}, toAPIError(err)
+	}
+	defer os.RemoveAll(outputDir)
+	args := append(slices.Clip(job.args), outputDirFlag, outputDir)
+
+	enginePath := resolveEnginePath(s.engineOverride(job.engine), job.projectRoot)
+	_, runSpan := s.tracer.Start(ctx, "engine.run", trace.WithAttributes(jobAttributes(job)...))
+	done := s.engines.start()
+	started := time.Now()
+	var output []byte
+	runCtx, onLine, stdout := s.captureStdout(ctx, progressLineHandler(job.onProgress))
+	if s.cfg.engineNice > 0 {
+		runCtx = withEngineNice(runCtx, s.cfg.engineNice)
+	}
+	if job.onProgress != nil {
+		output, err = s.deps.streamEngine(runCtx, s.engineWorkDir(job), enginePath, onLine, args...)
+	} else {
+		output, err = s.deps.runEngine(runCtx, s.engineWorkDir(job), enginePath, args...)
+	}
+	done()
+	s.logEngineStdout(job, stdout)
+	endSpan(runSpan, err)
+	engineTime := time.Since(started)
+	computedAt := time.Now()
+	s.metrics.observeEngine(job.mode, engineTime)
+	if err != nil {
+		// The request's own deadline or disconnect takes precedence over
+		// ENGINE_TIMEOUT, which is derived from it.
+		if parent.Err() != nil {
+			return engineResult{}, errCanceled(parent.Err())
+		}
+		// Shutdown kills the whole process group, which would otherwise
+		// read as a SIGKILL crash.
+		if s.engines.ctx.Err() != nil {
+			return engineResult{}, errShuttingDown(s.engines.ctx.Err())
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return engineResult{}, s.withModelPath(toAPIError(&engineTimeoutError{timeout: s.cfg.engineTimeout}), job)
+		}
+		// A binary that vanished after startup is an outage, not a bad
+		// request, so load balancers should route around this instance.
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			s.deps.logger.Error("engine binary unavailable",
+				"request_id", job.requestID,
+				"engine", enginePath,
+				"error", err.Error())
+			return engineResult{}, newAPIError(http.StatusServiceUnavailable, codeEngineDown,
+				"engine is unavailable").wrap(err)
+		}
+		var exitErr *exec.ExitError
+		s.deps.logger.Warn("engine failed",
+			"request_id", job.requestID,
+			"args", job.args,
+			"error", err.Error(),
+			"output", strings.TrimSpace(string(output)))
+		runErr := &engineRunError{output: s.redactModelPath(string(output), job), err: err}
+		if errors.As(err, &exitErr) {
+			runErr.signal = exitSignal(exitErr)
+		}
+		apiErr := s.withModelPath(toAPIError(runErr), job)
+		if exitErr != nil && exitErr.ExitCode() >= 0 {
+			apiErr.withDetail("exitCode", exitErr.ExitCode())
+		}
+		if runErr.signal != "" {
+			apiErr.withDetail("signal", runErr.signal)
+		}
+		return engineResult{}, apiErr
+	}
+
+	resultPath := filepath.Join(outputDir, job.resultFileName)
+	_, readSpan := s.tracer.Start(ctx, "engine.read_result")
+	payload, err := readFileContext(ctx, s.deps.readFile, resultPath)
+	endSpan(readSpan, err)
+	if err != nil {
+		if parent.Err() != nil {
+			return engineResult{}, errCanceled(parent.Err())
+		}
+		if s.engines.ctx.Err() != nil {
+			return engineResult{}, errShuttingDown(s.engines.ctx.Err())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return engineResult{}, s.withModelPath(toAPIError(&engineTimeoutError{timeout: s.cfg.engineTimeout}), job)
+		}
+		return engineResult{}, toAPIError(&resultReadError{fileName: job.resultFileName, err: err})
+	}
+	if err := checkResultShape(job, payload); err != nil {
+		s.deps.logger.Warn("engine result corrupt",
+			"request_id", job.requestID,
+			"args", job.args,
+			"error", err.Error())
+		_ = os.Remove(resultPath)
+		return engineResult{}, err
+	}
+	return engineResult{payload: payload, engineTime: engineTime, computedAt: computedAt}, nil
+}
+
+// Top-level keys the engine writes for each family of modes.
+var (
+	pathResultKeys  = []string{"path"}
+	curveResultKeys = []string{"curves"}
+	mstResultKeys   = []string{"edges", "totalWeight"}
+	// endpoints is the vertex pair the diameter runs between.
+	diameterResultKeys = []string{"diameter", "endpoints"}
+)
+
+// checkResultShape rejects result files that are not JSON or lack the keys
+// job's mode always writes, as a truncated file from a crashed engine would.
+// Modes with array results also accept a top-level array.
+func checkResultShape(job engineJob, payload []byte) *apiError {
+	corrupt := func(message string) *apiError {
+		return newAPIError(http.StatusBadGateway, codeResultCorrupt, message).
+			withDetail("resultFile", job.resultFileName)
+	}
+	if !json.Valid(payload) {
+		return corrupt("engine produced invalid JSON")
+	}
+	if job.arrayResult && bytes.HasPrefix(bytes.TrimLeft(payload, " \t\r\n"), []byte("[")) {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return corrupt("engine result is not a JSON object")
+	}
+	for _, key := range job.resultKeys {
+		if _, ok := fields[key]; !ok {
+			return corrupt(fmt.Sprintf("engine result is missing %q", key)).withDetail("missingKey", key)
+		}
+	}
+	return nil
+}
+
+// acquireEngineSlot waits up to ENGINE_SLOT_WAIT for one of the
+// MAX_CONCURRENCY engine slots, returning ENGINE_BUSY if none frees up, or
+// CANCELED if ctx ends first. At most MAX_QUEUE callers wait at once; the
+// rest get ENGINE_BUSY straight away.
+func (s *server) acquireEngineSlot(ctx context.Context) (func(), error) {
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	default:
+	}
+	select {
+	case s.waiting <- struct{}{}:
+		defer func() { <-s.waiting }()
+	default:
+		return nil, newAPIError(503, codeEngineBusy, "engine queue is full, retry shortly").
+			withDetail("queueSize", cap(s.waiting)).
+			withRetryAfter(s.cfg.slotWait)
+	}
+
+	timer := time.NewTimer(s.cfg.slotWait)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots }, nil
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, errCanceled(ctx.Err())
+	}
+	return nil, newAPIError(503, codeEngineBusy, "all engine slots are busy, retry shortly").
+		withRetryAfter(s.cfg.slotWait)
+}
+
+// readFileContext runs readFile in the background so a slow disk cannot
+// outlive ctx.
+func readFileContext(ctx context.Context, readFile func(string) ([]byte, error), path string) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := readFile(path)
+		done <- result{data: data, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
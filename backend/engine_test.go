@@ -0,0 +1,860 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResolveEnginePath(t *testing.T) {
+	const projectRoot = "/srv/geodesic"
+	cases := []struct {
+		name     string
+		override string
+		expected string
+	}{
+		{name: "default", override: "", expected: filepath.Join(projectRoot, "main")},
+		{name: "absolute override", override: "/opt/engine/bin/main", expected: "/opt/engine/bin/main"},
+		{name: "relative override", override: "build/main", expected: filepath.Join(projectRoot, "build", "main")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := resolveEnginePath(tc.override, projectRoot); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestEnginePathEnvOverridesDefault(t *testing.T) {
+	t.Setenv("ENGINE_PATH", "/opt/engine/main")
+	enginePath := ""
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePathArg string, args ...string) ([]byte, error) {
+			enginePath = enginePathArg
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if enginePath != "/opt/engine/main" {
+		t.Fatalf("expected ENGINE_PATH to be used, got %q", enginePath)
+	}
+}
+
+func TestRequestSelectsNamedEngine(t *testing.T) {
+	t.Setenv("ENGINE_PATH", "/opt/engine/main")
+	t.Setenv("ENGINES", "stable=/opt/engine/main, next = /opt/engine-next/main, broken")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	enginePath := ""
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePathArg string, args ...string) ([]byte, error) {
+			enginePath = enginePathArg
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[0,1]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name   string
+		engine string
+		want   string
+		status int
+	}{
+		{name: "default", want: "/opt/engine/main", status: http.StatusOK},
+		{name: "named", engine: "next", want: "/opt/engine-next/main", status: http.StatusOK},
+		{name: "unknown", engine: "broken", status: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			enginePath = ""
+			body := fmt.Sprintf(`{"start":0,"end":1,"model":"mesh.obj","engine":%q}`, tc.engine)
+			w := performRequest(router, http.MethodPost, "/compute", body)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status != http.StatusOK {
+				if details := errorDetails(t, decodeJSONBody(t, w)); details["field"] != "engine" {
+					t.Fatalf("expected the engine field to be blamed, got %v", details)
+				}
+				return
+			}
+			if enginePath != tc.want {
+				t.Fatalf("expected engine %q, got %q", tc.want, enginePath)
+			}
+		})
+	}
+}
+
+func TestCheckEngineExecutable(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "main")
+	plain := filepath.Join(dir, "main.txt")
+	if err := os.WriteFile(executable, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("failed to write executable: %v", err)
+	}
+	if err := os.WriteFile(plain, []byte("text"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if err := checkEngineExecutable(executable); err != nil {
+		t.Fatalf("expected executable to pass, got %v", err)
+	}
+	for _, path := range []string{plain, dir, filepath.Join(dir, "missing")} {
+		if err := checkEngineExecutable(path); err == nil {
+			t.Fatalf("expected %s to be rejected", path)
+		}
+	}
+}
+
+func TestEngineConcurrencyIsCapped(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "2")
+	t.Setenv("ENGINE_SLOT_WAIT", "5s")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	const requests = 5
+	codes := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		go func(end int) {
+			body := fmt.Sprintf(`{"start":0,"end":%d,"model":"mesh.obj"}`, end)
+			codes <- performRequest(router, http.MethodPost, "/compute", body).Code
+		}(i + 1)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for running.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := running.Load(); got != 2 {
+		t.Fatalf("expected exactly 2 engines running, got %d", got)
+	}
+	close(release)
+
+	for i := 0; i < requests; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Fatalf("expected queued requests to complete with 200, got %d", code)
+		}
+	}
+	if got := peak.Load(); got != 2 {
+		t.Fatalf("expected peak concurrency 2, got %d", got)
+	}
+}
+
+func TestEngineBusyReturnsServiceUnavailable(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "1")
+	t.Setenv("ENGINE_SLOT_WAIT", "20ms")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			close(started)
+			<-release
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	first := make(chan int, 1)
+	go func() {
+		first <- performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`).Code
+	}()
+	<-started
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":2,"model":"mesh.obj"}`)
+	close(release)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("expected Retry-After 1, got %q", got)
+	}
+	if code := decodeJSONBody(t, w)["code"]; code != string(codeEngineBusy) {
+		t.Fatalf("expected ENGINE_BUSY, got %#v", code)
+	}
+	if code := <-first; code != http.StatusOK {
+		t.Fatalf("expected the running request to succeed, got %d", code)
+	}
+}
+
+func TestFullEngineQueueFailsFast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("MAX_CONCURRENCY", "1")
+	t.Setenv("MAX_QUEUE", "1")
+	t.Setenv("ENGINE_SLOT_WAIT", "5s")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	s := newServer(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			started <- struct{}{}
+			<-release
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[0,1]}`), nil
+		},
+	})
+	router := s.router()
+
+	codes := make(chan int, 2)
+	for end := 1; end <= 2; end++ {
+		go func() {
+			body := fmt.Sprintf(`{"start":0,"end":%d,"model":"mesh.obj"}`, end)
+			codes <- performRequest(router, http.MethodPost, "/compute", body).Code
+		}()
+		if end == 1 {
+			<-started
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(s.waiting) < 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	began := time.Now()
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":3,"model":"mesh.obj"}`)
+	elapsed := time.Since(began)
+	close(release)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" {
+		t.Fatalf("expected 503 with Retry-After 5, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if elapsed > time.Second {
+		t.Fatalf("expected the overflow request to fail fast, took %s", elapsed)
+	}
+	if details := errorDetails(t, decodeJSONBody(t, w)); details["queueSize"] != float64(1) {
+		t.Fatalf("expected queueSize 1 in details, got %v", details)
+	}
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Fatalf("expected the running and queued requests to succeed, got %d", code)
+		}
+	}
+}
+
+// fakeEngineCommand replaces execCommand with a re-exec of the test binary
+// running TestFakeEngineProcess in the given behavior.
+func fakeEngineCommand(t *testing.T, behavior string) {
+	t.Helper()
+	original := execCommand
+	t.Cleanup(func() { execCommand = original })
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		cmdArgs := append([]string{"-test.run=TestFakeEngineProcess", "--", name}, args...)
+		cmd := exec.CommandContext(ctx, os.Args[0], cmdArgs...)
+		cmd.Env = append(os.Environ(), "FAKE_ENGINE_BEHAVIOR="+behavior)
+		return cmd
+	}
+}
+
+// TestFakeEngineProcess is not a real test: it is the engine stand-in
+// launched by fakeEngineCommand.
+func TestFakeEngineProcess(t *testing.T) {
+	behavior := os.Getenv("FAKE_ENGINE_BEHAVIOR")
+	if behavior == "" {
+		return
+	}
+	switch behavior {
+	case "success":
+		_, outputDir := splitOutputDir(os.Args)
+		fmt.Println("PROGRESS 100")
+		if err := os.WriteFile(filepath.Join(outputDir, "result.json"), []byte(`{"path":[0,4]}`), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "fail":
+		fmt.Fprintln(os.Stderr, "Error: start vertex out of range")
+		os.Exit(1)
+	case "echo-pair":
+		// Hold the run open long enough for concurrent requests to overlap,
+		// then write a result identifying which pair produced it.
+		args, outputDir := splitOutputDir(os.Args)
+		time.Sleep(50 * time.Millisecond)
+		result := fmt.Sprintf(`{"start":%s,"end":%s,"path":[]}`, args[4], args[5])
+		if err := os.WriteFile(filepath.Join(outputDir, "result.json"), []byte(result), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "noisy-fail":
+		fmt.Println("PROGRESS 40")
+		fmt.Println("loaded 10 vertices")
+		fmt.Fprintln(os.Stderr, "Error: mesh has no faces")
+		os.Exit(2)
+	case "stdout-fail":
+		fmt.Println("Error: analytic surface not recognised")
+		os.Exit(2)
+	case "partial-fail":
+		// Die after starting to write the result, as a killed engine would.
+		_, outputDir := splitOutputDir(os.Args)
+		os.WriteFile(filepath.Join(outputDir, "result.json"), []byte(`{"path":[0,`), 0o644)
+		os.Exit(1)
+	case "hang":
+		// Start a grandchild in the same process group, record both PIDs,
+		// then wait to be killed.
+		child := exec.Command("sleep", "30")
+		if err := child.Start(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		pids := fmt.Sprintf("%d %d", os.Getpid(), child.Process.Pid)
+		os.WriteFile(os.Getenv("FAKE_ENGINE_PIDFILE"), []byte(pids), 0o644)
+		time.Sleep(30 * time.Second)
+	case "no-path":
+		// What the engine writes when start and end are not connected.
+		_, outputDir := splitOutputDir(os.Args)
+		fmt.Println("Target Distance: (unreachable)")
+		result := `{"inputFileName":"mesh.obj","reachable":false,"totalDistance":null,"path":[],"elapsedMs":0.01,"allDistances":[0]}`
+		if err := os.WriteFile(filepath.Join(outputDir, "result.json"), []byte(result), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "no-output":
+		// Exit cleanly without writing a result file.
+	case "crash":
+		// Die by a signal, as a segfaulting engine would.
+		self, _ := os.FindProcess(os.Getpid())
+		self.Kill()
+		time.Sleep(time.Second)
+	}
+	os.Exit(0)
+}
+
+func TestRunEngineWithDefaultRunnerSuccess(t *testing.T) {
+	fakeEngineCommand(t, "success")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w.Body.String() != `{"path":[0,4]}` {
+		t.Fatalf("expected the engine's result file, got %q", w.Body.String())
+	}
+}
+
+func TestRunEngineWithDefaultRunnerReportsEngineError(t *testing.T) {
+	fakeEngineCommand(t, "fail")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if body["code"] != string(codeEngineFailed) {
+		t.Fatalf("expected ENGINE_FAILED, got %v", body["code"])
+	}
+	if body["message"] != "Error: start vertex out of range" {
+		t.Fatalf("expected engine output as message, got %v", body["message"])
+	}
+}
+
+func TestRunEngineWithDefaultRunnerMissingResultFile(t *testing.T) {
+	fakeEngineCommand(t, "no-output")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	// A result left in the engine's old default output location must never
+	// be mistaken for this run's output.
+	stale := filepath.Join(projectRoot, "frontend", "public", "result.json")
+	if err := os.WriteFile(stale, []byte(`{"path":[9,9,9]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected status 502, got %d (%s)", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if body["code"] != string(codeResultMissing) || body["message"] != "engine exited without writing result.json" {
+		t.Fatalf("expected RESULT_MISSING for result.json, got %v", body)
+	}
+}
+
+func TestConcurrentRunsDoNotShareResultFiles(t *testing.T) {
+	t.Setenv("MAX_CONCURRENCY", "2")
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	fakeEngineCommand(t, "echo-pair")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	pairs := [][2]int{{1, 2}, {7, 3}}
+	bodies := make([]string, len(pairs))
+	var wg sync.WaitGroup
+	for i, pair := range pairs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := performRequest(router, http.MethodPost, "/compute",
+				fmt.Sprintf(`{"start":%d,"end":%d,"model":"mesh.obj"}`, pair[0], pair[1]))
+			bodies[i] = w.Body.String()
+		}()
+	}
+	wg.Wait()
+
+	for i, pair := range pairs {
+		expected := fmt.Sprintf(`{"start":%d,"end":%d,"path":[]}`, pair[0], pair[1])
+		if bodies[i] != expected {
+			t.Fatalf("request %d: expected its own result %s, got %s", i, expected, bodies[i])
+		}
+	}
+	if leftovers, _ := os.ReadDir(modeOutputDir(tmpDir, "dijkstra")); len(leftovers) != 0 {
+		t.Fatalf("expected per-run output directories to be removed, found %d", len(leftovers))
+	}
+}
+
+func TestMissingEngineBinaryReturnsServiceUnavailable(t *testing.T) {
+	t.Setenv("ENGINE_PATH", filepath.Join(t.TempDir(), "deleted-engine"))
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if body["code"] != string(codeEngineDown) {
+		t.Fatalf("expected code %s, got %v", codeEngineDown, body["code"])
+	}
+	if strings.Contains(w.Body.String(), "deleted-engine") {
+		t.Fatalf("expected the engine path not to leak to the client, got %s", w.Body.String())
+	}
+}
+
+func TestEngineOutputDirIsWorkDirAndResultBase(t *testing.T) {
+	outputBase := t.TempDir()
+	t.Setenv("ENGINE_OUTPUT_DIR", outputBase)
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var workDir, runDir, readPath string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, dir, enginePath string, args ...string) ([]byte, error) {
+			workDir = dir
+			_, runDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			readPath = path
+			return []byte(`{"path":[1,2]}`), nil
+		},
+	})
+
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":1,"end":2,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if workDir != outputBase {
+		t.Fatalf("expected the engine to run in %s, got %s", outputBase, workDir)
+	}
+	if modeDir := modeOutputDir(outputBase, "dijkstra"); filepath.Dir(runDir) != modeDir {
+		t.Fatalf("expected the run's output dir under %s, got %s", modeDir, runDir)
+	}
+	if readPath != filepath.Join(runDir, "result.json") {
+		t.Fatalf("expected the result to be read from %s, got %s", runDir, readPath)
+	}
+	if strings.HasPrefix(readPath, projectRoot) {
+		t.Fatalf("expected nothing to be read from the project root, got %s", readPath)
+	}
+}
+
+func TestEngineStdoutIsLoggedOnlyInDebug(t *testing.T) {
+	for _, debug := range []bool{true, false} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			t.Setenv("DEBUG", fmt.Sprint(debug))
+			fakeEngineCommand(t, "success")
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			var logs bytes.Buffer
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				logger:             slog.New(slog.NewTextHandler(&logs, nil)),
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "PROGRESS") {
+				t.Fatalf("expected the result without engine output, got %d %s", w.Code, w.Body.String())
+			}
+			logged := strings.Contains(logs.String(), `msg="engine stdout"`) &&
+				strings.Contains(logs.String(), "PROGRESS 100") &&
+				strings.Contains(logs.String(), "request_id="+w.Header().Get(requestIDHeader))
+			if logged != debug {
+				t.Fatalf("expected stdout to be logged=%v, got logs:\n%s", debug, logs.String())
+			}
+		})
+	}
+}
+
+func TestRunEngineSurfacesStderrAndExitCode(t *testing.T) {
+	cases := []struct {
+		behavior string
+		message  string
+	}{
+		{behavior: "noisy-fail", message: "Error: mesh has no faces"},
+		{behavior: "stdout-fail", message: "Error: analytic surface not recognised"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.behavior, func(t *testing.T) {
+			fakeEngineCommand(t, tc.behavior)
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("expected status 500, got %d", w.Code)
+			}
+			body := decodeJSONBody(t, w)
+			if body["message"] != tc.message {
+				t.Fatalf("expected message %q, got %q", tc.message, body["message"])
+			}
+			if code := errorDetails(t, body)["exitCode"]; code != float64(2) {
+				t.Fatalf("expected exitCode 2 in details, got %v", code)
+			}
+			if body["code"] != string(codeEngineFailed) || errorDetails(t, body)["signal"] != nil {
+				t.Fatalf("expected a clean non-zero exit to be ENGINE_FAILED without a signal, got %v", body)
+			}
+		})
+	}
+}
+
+func TestRunEngineNoPathIsNotACrash(t *testing.T) {
+	cases := []struct {
+		behavior string
+		status   int
+		want     string
+		warning  string
+	}{
+		{behavior: "no-path", status: http.StatusOK, want: `"reachable":false`, warning: noPathWarning},
+		{behavior: "success", status: http.StatusOK, want: `"path":[0,4]`},
+		{behavior: "fail", status: http.StatusInternalServerError, want: string(codeEngineFailed)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.behavior, func(t *testing.T) {
+			fakeEngineCommand(t, tc.behavior)
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tc.want) {
+				t.Fatalf("expected body to contain %s, got %s", tc.want, w.Body.String())
+			}
+			if got := w.Header().Get("Warning"); got != tc.warning {
+				t.Fatalf("expected Warning %q, got %q", tc.warning, got)
+			}
+		})
+	}
+}
+
+func TestRunEngineRetriesTransientResultReadFailure(t *testing.T) {
+	var logs bytes.Buffer
+	runs, reads := 0, 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			reads++
+			if reads == 1 {
+				return nil, errors.New("result.json: no such file or directory")
+			}
+			return []byte(`{"path":[0,4]}`), nil
+		},
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 after a retry, got %d: %s", w.Code, w.Body.String())
+	}
+	if runs != 2 {
+		t.Fatalf("expected the engine to run twice, got %d", runs)
+	}
+	id := w.Header().Get(requestIDHeader)
+	if !strings.Contains(logs.String(), `msg="retrying engine run" request_id=`+id) {
+		t.Fatalf("expected the retry to be logged with request ID %s, got %q", id, logs.String())
+	}
+}
+
+func TestRunEngineRecomputesCorruptResultOnce(t *testing.T) {
+	cases := []struct {
+		name     string
+		payloads []string
+		status   int
+		runs     int
+	}{
+		{name: "recovers", payloads: []string{`{"path":[0,`, `{"path":[0,4]}`}, status: http.StatusOK, runs: 2},
+		{name: "gives up", payloads: []string{`{"path":[0,`, `{"path":[0,`, `{"path":[0,4]}`}, status: http.StatusBadGateway, runs: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			runs := 0
+			var resultPaths []string
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, _, _ string, args ...string) ([]byte, error) {
+					_, outputDir := splitOutputDir(args)
+					resultPath := filepath.Join(outputDir, "result.json")
+					resultPaths = append(resultPaths, resultPath)
+					runs++
+					return nil, os.WriteFile(resultPath, []byte(tc.payloads[runs-1]), 0o644)
+				},
+				logger: slog.New(slog.NewTextHandler(&logs, nil)),
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if runs != tc.runs {
+				t.Fatalf("expected the engine to run %d times, got %d", tc.runs, runs)
+			}
+			if !strings.Contains(logs.String(), `msg="recomputing corrupt engine result"`) {
+				t.Fatalf("expected the recompute to be logged, got %q", logs.String())
+			}
+			for _, path := range resultPaths {
+				if _, err := os.Stat(path); !os.IsNotExist(err) {
+					t.Fatalf("expected the result file %s to be deleted, got %v", path, err)
+				}
+			}
+			if tc.status == http.StatusOK {
+				w = performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+				if w.Header().Get(cacheStatusHeader) != string(cacheHit) || w.Body.String() != `{"path":[0,4]}` {
+					t.Fatalf("expected the recomputed result to be cached, got %s %s", w.Header().Get(cacheStatusHeader), w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunEngineDoesNotRetryEngineExitErrors(t *testing.T) {
+	runs := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs++
+			return []byte("Error: start vertex out of range"), errors.New("exit status 1")
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	if runs != 1 {
+		t.Fatalf("expected a single engine run, got %d", runs)
+	}
+}
+
+func TestConcurrentIdenticalRequestsShareOneEngineRun(t *testing.T) {
+	var runs atomic.Int32
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+	})
+
+	const callers = 10
+	codes := make([]int, callers)
+	bodies := make([]string, callers)
+	statuses := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			codes[i], bodies[i], statuses[i] = w.Code, w.Body.String(), w.Header().Get(cacheStatusHeader)
+		}()
+	}
+	wg.Wait()
+
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("expected exactly one engine run, got %d", n)
+	}
+	misses := 0
+	for i := range codes {
+		if codes[i] != http.StatusOK || bodies[i] != `{"path":[0,4]}` {
+			t.Fatalf("caller %d: expected the shared result, got %d %s", i, codes[i], bodies[i])
+		}
+		switch cacheStatus(statuses[i]) {
+		case cacheMiss:
+			misses++
+		case cacheHitShared, cacheHit:
+		default:
+			t.Fatalf("caller %d: unexpected %s %q", i, cacheStatusHeader, statuses[i])
+		}
+	}
+	if misses != 1 {
+		t.Fatalf("expected only the caller that ran the engine to see MISS, got %d", misses)
+	}
+}
+
+func TestSharedEngineFailuresAreNotCached(t *testing.T) {
+	runs := 0
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs++
+			if runs == 1 {
+				return []byte("Error: out of memory"), errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+	})
+
+	first := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	second := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if first.Code != http.StatusInternalServerError || second.Code != http.StatusOK {
+		t.Fatalf("expected a failure then a fresh success, got %d then %d", first.Code, second.Code)
+	}
+	if runs != 2 {
+		t.Fatalf("expected the engine to run again after a failure, got %d runs", runs)
+	}
+}
+
+func TestRunEngineRejectsCorruptResults(t *testing.T) {
+	cases := []struct {
+		name    string
+		route   string
+		payload string
+		status  int
+	}{
+		{name: "valid path", route: "/compute", payload: `{"path":[0,4],"reachable":true}`, status: http.StatusOK},
+		{name: "valid curves", route: "/heat", payload: `{"curves":[[0,1]]}`, status: http.StatusOK},
+		{name: "truncated", route: "/compute", payload: `{"path":[0,`, status: http.StatusBadGateway},
+		{name: "not an object", route: "/compute", payload: `"done"`, status: http.StatusBadGateway},
+		{name: "missing key", route: "/analytics", payload: `{"path":[0,4]}`, status: http.StatusBadGateway},
+		{name: "empty", route: "/compute", payload: ``, status: http.StatusBadGateway},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(tc.payload), nil },
+			})
+
+			w := performRequest(router, http.MethodPost, tc.route, `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			if body := decodeJSONBody(t, w); body["code"] != string(codeResultCorrupt) {
+				t.Fatalf("expected RESULT_CORRUPT, got %v", body)
+			}
+		})
+	}
+}
+
+func TestEngineWritesIntoModeOutputDir(t *testing.T) {
+	outputBase := t.TempDir()
+	t.Setenv("ENGINE_OUTPUT_DIR", outputBase)
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	modeDir := modeOutputDir(outputBase, "heat")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, args ...string) ([]byte, error) {
+			_, runDir := splitOutputDir(args)
+			if filepath.Dir(runDir) != modeDir {
+				t.Errorf("expected the run's output dir under %s, got %s", modeDir, runDir)
+			}
+			return nil, os.WriteFile(filepath.Join(runDir, "heat_result.json"), []byte(`[{"id":"heat"}]`), 0o644)
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/heat", `{"start":1,"end":2,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK || w.Body.String() != `[{"id":"heat"}]` {
+		t.Fatalf("expected the heat result written by the engine, got %d %s", w.Code, w.Body.String())
+	}
+	if info, err := os.Stat(modeDir); err != nil || !info.IsDir() {
+		t.Fatalf("expected the mode output dir %s to be created: %v", modeDir, err)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// envelopeInput echoes what a result was computed for, so a client firing
+// many requests in parallel can match responses to them. Start and End are
+// the resolved vertices and are omitted for whole-graph modes.
+type envelopeInput struct {
+	Model string `json:"model"`
+	Start *int   `json:"start,omitempty"`
+	End   *int   `json:"end,omitempty"`
+	Mode  string `json:"mode"`
+}
+
+// envelopeInputFor describes job for the envelope.
+func envelopeInputFor(job engineJob, route engineRoute) envelopeInput {
+	input := envelopeInput{Model: job.model, Mode: job.mode}
+	if !route.wholeGraph {
+		input.Start, input.End = &job.start, &job.end
+	}
+	return input
+}
+
+// wrapEnvelope returns {"input":...,"result":payload}. The payload is spliced
+// in as is rather than re-encoded, which would parse it again.
+func wrapEnvelope(input envelopeInput, payload []byte) []byte {
+	encoded, _ := json.Marshal(input)
+	var out bytes.Buffer
+	out.Grow(len(`{"input":,"result":}`) + len(encoded) + len(payload))
+	out.WriteString(`{"input":`)
+	out.Write(encoded)
+	out.WriteString(`,"result":`)
+	out.Write(bytes.TrimSpace(payload))
+	out.WriteByte('}')
+	return out.Bytes()
+}
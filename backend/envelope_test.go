@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newEnvelopeRouter(t *testing.T) http.Handler {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			if strings.HasSuffix(path, "mst_result.json") {
+				return []byte(`{"edges":[],"totalWeight":0}`), nil
+			}
+			return []byte(`{"path":[1,3,4]}`), nil
+		},
+	})
+}
+
+func TestEnvelopeEchoesTheInput(t *testing.T) {
+	router := newEnvelopeRouter(t)
+
+	w := performRequest(router, http.MethodPost, "/compute?envelope=true", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	want := `{"input":{"model":"mesh.obj","start":1,"end":4,"mode":"dijkstra"},"result":{"path":[1,3,4]}}`
+	if w.Body.String() != want {
+		t.Fatalf("expected %s, got %s", want, w.Body.String())
+	}
+
+	w = performRequest(router, http.MethodGet, "/compute_mst?model=mesh.obj&envelope=1", "")
+	if body := decodeJSONBody(t, w); body["input"] == nil {
+		t.Fatalf("expected the GET route to be enveloped, got %s", w.Body.String())
+	} else if input := body["input"].(map[string]any); input["mode"] != "mst" || input["start"] != nil {
+		t.Fatalf("expected a whole-graph input without endpoints, got %v", input)
+	}
+}
+
+func TestResponsesAreBareWithoutEnvelope(t *testing.T) {
+	router := newEnvelopeRouter(t)
+
+	for _, path := range []string{"/compute", "/compute?envelope=false"} {
+		w := performRequest(router, http.MethodPost, path, `{"start":1,"end":4,"model":"mesh.obj"}`)
+		if w.Code != http.StatusOK || w.Body.String() != `{"path":[1,3,4]}` {
+			t.Fatalf("%s: expected the bare payload, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	w := performRequest(router, http.MethodPost, "/compute?envelope=maybe", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an invalid envelope flag, got %d", w.Code)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorCode is the machine-readable reason attached to every error response
+// so clients can branch on the failure type instead of parsing messages.
+type errorCode string
+
+const (
+	codeInvalidInput     errorCode = "INVALID_INPUT"
+	codeModelNotFound    errorCode = "MODEL_NOT_FOUND"
+	codeModelExists      errorCode = "MODEL_EXISTS"
+	codeModelFetchFailed errorCode = "MODEL_FETCH_FAILED"
+	codeEmptyModel       errorCode = "EMPTY_MODEL"
+	codeUnsupportedModel errorCode = "UNSUPPORTED_MODEL_TYPE"
+	codeUnsupportedMedia errorCode = "UNSUPPORTED_MEDIA_TYPE"
+	codePayloadTooLarge  errorCode = "PAYLOAD_TOO_LARGE"
+	codeEngineFailed     errorCode = "ENGINE_FAILED"
+	codeEngineCrashed    errorCode = "ENGINE_CRASHED"
+	codeEngineTimeout    errorCode = "ENGINE_TIMEOUT"
+	codeResultUnreadable errorCode = "RESULT_UNREADABLE"
+	codeResultCorrupt    errorCode = "RESULT_CORRUPT"
+	codeResultMissing    errorCode = "RESULT_MISSING"
+	codeEngineBusy       errorCode = "ENGINE_BUSY"
+	codeEngineDown       errorCode = "ENGINE_UNAVAILABLE"
+	codeCircuitOpen      errorCode = "ENGINE_CIRCUIT_OPEN"
+	codeNegativeCycle    errorCode = "NEGATIVE_CYCLE"
+	codeRateLimited      errorCode = "RATE_LIMITED"
+	codeKeyReused        errorCode = "IDEMPOTENCY_KEY_REUSED"
+	codeCanceled         errorCode = "CANCELED"
+	codeRequestTimeout   errorCode = "REQUEST_TIMEOUT"
+	codeUnauthorized     errorCode = "UNAUTHORIZED"
+	codeForbidden        errorCode = "FORBIDDEN"
+	codeNotFound         errorCode = "NOT_FOUND"
+	codeMethodNotAllowed errorCode = "METHOD_NOT_ALLOWED"
+	codeInternal         errorCode = "INTERNAL"
+)
+
+// apiError is an error that knows how it should be rendered to the client.
+type apiError struct {
+	status     int
+	code       errorCode
+	message    string
+	details    map[string]any
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *apiError) Error() string { return e.message }
+
+func (e *apiError) Unwrap() error { return e.err }
+
+func newAPIError(status int, code errorCode, message string) *apiError {
+	return &apiError{status: status, code: code, message: message}
+}
+
+// withDetail attaches a key to the "details" object of the response.
+func (e *apiError) withDetail(key string, value any) *apiError {
+	if e.details == nil {
+		e.details = map[string]any{}
+	}
+	e.details[key] = value
+	return e
+}
+
+// withRetryAfter asks the client to back off before retrying.
+func (e *apiError) withRetryAfter(d time.Duration) *apiError {
+	e.retryAfter = d
+	return e
+}
+
+// wrap records the underlying cause for errors.Is/As without exposing it.
+func (e *apiError) wrap(err error) *apiError {
+	e.err = err
+	return e
+}
+
+func errProjectRoot(err error) *apiError {
+	return newAPIError(http.StatusInternalServerError, codeInternal,
+		"failed to determine project root").wrap(err)
+}
+
+// statusClientClosedRequest is the non-standard status recorded when the
+// client goes away before its result is ready; nobody is left to read it.
+const statusClientClosedRequest = 499
+
+// errCanceled reports a request whose context ended before its result was
+// ready: either the client went away or REQUEST_TIMEOUT expired.
+func errCanceled(err error) *apiError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return newAPIError(http.StatusServiceUnavailable, codeRequestTimeout, "request timed out").wrap(err)
+	}
+	return newAPIError(statusClientClosedRequest, codeCanceled, "request canceled").wrap(err)
+}
+
+// errShuttingDown reports an engine run that graceful shutdown killed after
+// the grace period. The engine was not at fault, so this is not a crash.
+func errShuttingDown(err error) *apiError {
+	return newAPIError(http.StatusServiceUnavailable, codeCanceled, "server is shutting down").wrap(err)
+}
+
+type errorResponse struct {
+	Code    errorCode      `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// toAPIError maps any error returned by the request pipeline to an apiError.
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var vErr *validationError
+	var timeoutErr *engineTimeoutError
+	var runErr *engineRunError
+	var readErr *resultReadError
+	switch {
+	case errors.As(err, &vErr):
+		return &apiError{
+			status:  http.StatusUnprocessableEntity,
+			code:    codeInvalidInput,
+			message: vErr.message,
+			details: vErr.details,
+			err:     err,
+		}
+	case errors.As(err, &timeoutErr):
+		return newAPIError(http.StatusGatewayTimeout, codeEngineTimeout, err.Error()).wrap(err)
+	case errors.As(err, &runErr) && runErr.signal != "":
+		return newAPIError(http.StatusInternalServerError, codeEngineCrashed, err.Error()).wrap(err)
+	case errors.As(err, &runErr):
+		return newAPIError(http.StatusInternalServerError, codeEngineFailed, err.Error()).wrap(err)
+	case errors.As(err, &readErr) && errors.Is(err, fs.ErrNotExist):
+		// Every run writes into a fresh directory, so a missing file means
+		// the engine exited cleanly without producing a result.
+		return newAPIError(http.StatusBadGateway, codeResultMissing,
+			"engine exited without writing "+readErr.fileName).wrap(err)
+	case errors.As(err, &readErr):
+		return newAPIError(http.StatusInternalServerError, codeResultUnreadable, err.Error()).wrap(err)
+	default:
+		return newAPIError(http.StatusInternalServerError, codeInternal, "internal server error").wrap(err)
+	}
+}
+
+func respondError(c *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.retryAfter > 0 {
+		secs := int(math.Ceil(apiErr.retryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(secs))
+	}
+	c.AbortWithStatusJSON(apiErr.status, errorResponse{
+		Code:    apiErr.code,
+		Message: apiErr.message,
+		Details: apiErr.details,
+	})
+}
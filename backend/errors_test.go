@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEngineFailuresMapToErrorCodes(t *testing.T) {
+	cases := []struct {
+		name       string
+		runEngine  func(ctx context.Context, projectRoot, enginePath string, args ...string) ([]byte, error)
+		readFile   func(path string) ([]byte, error)
+		body       string
+		wantStatus int
+		wantCode   errorCode
+	}{
+		{
+			name:       "invalid JSON",
+			body:       `{`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeInvalidInput,
+		},
+		{
+			name:       "out of range",
+			body:       `{"start":-1,"end":1,"model":"mesh.obj"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   codeInvalidInput,
+		},
+		{
+			name: "engine failed",
+			runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+				return []byte("boom"), errors.New("exit status 1")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeEngineFailed,
+		},
+		{
+			name: "engine timeout",
+			runEngine: func(ctx context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   codeEngineTimeout,
+		},
+		{
+			name: "result unreadable",
+			readFile: func(path string) ([]byte, error) {
+				return nil, errors.New("missing file")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeResultUnreadable,
+		},
+		{
+			name: "result missing",
+			readFile: func(path string) ([]byte, error) {
+				return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+			},
+			wantStatus: http.StatusBadGateway,
+			wantCode:   codeResultMissing,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ENGINE_TIMEOUT", "20ms")
+			deps := appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return newTestProject(t, "mesh.obj", 16), nil
+				},
+				runEngine: tc.runEngine,
+				readFile:  tc.readFile,
+			}
+			if deps.runEngine == nil {
+				deps.runEngine = func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					return []byte(""), nil
+				}
+			}
+			if deps.readFile == nil {
+				deps.readFile = func(path string) ([]byte, error) {
+					return []byte(`{"path":[],"curves":[]}`), nil
+				}
+			}
+			body := tc.body
+			if body == "" {
+				body = `{"start":0,"end":1,"model":"mesh.obj"}`
+			}
+
+			w := performRequest(newTestRouter(deps), http.MethodPost, "/compute", body)
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			resp := decodeJSONBody(t, w)
+			if got := resp["code"]; got != string(tc.wantCode) {
+				t.Fatalf("expected code %s, got %#v", tc.wantCode, got)
+			}
+			if msg, _ := resp["message"].(string); msg == "" {
+				t.Fatalf("expected a human-readable message")
+			}
+		})
+	}
+}
+
+func TestToAPIErrorHidesUnexpectedErrors(t *testing.T) {
+	apiErr := toAPIError(errors.New("open /secret/path: permission denied"))
+	if apiErr.status != http.StatusInternalServerError || apiErr.code != codeInternal {
+		t.Fatalf("expected INTERNAL 500, got %s %d", apiErr.code, apiErr.status)
+	}
+	if apiErr.message != "internal server error" {
+		t.Fatalf("expected generic message, got %q", apiErr.message)
+	}
+}
+
+func TestToAPIErrorPreservesCause(t *testing.T) {
+	cause := &engineTimeoutError{timeout: time.Second}
+	if !errors.Is(toAPIError(cause), cause) {
+		t.Fatalf("expected cause to be reachable through errors.Is")
+	}
+}
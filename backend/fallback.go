@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleNoRoute answers unknown paths in the JSON error format.
+func handleNoRoute(c *gin.Context) {
+	respondError(c, newAPIError(http.StatusNotFound, codeNotFound, "no route for "+c.Request.URL.Path))
+}
+
+// handleNoMethod answers a known path requested with the wrong method,
+// listing the methods it does accept in the Allow header.
+func handleNoMethod(r *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed := allowedMethods(r.Routes(), c.Request.URL.Path)
+		if len(allowed) > 0 {
+			c.Header("Allow", strings.Join(allowed, ", "))
+		}
+		respondError(c, newAPIError(http.StatusMethodNotAllowed, codeMethodNotAllowed,
+			c.Request.Method+" is not allowed on "+c.Request.URL.Path).
+			withDetail("allowed", allowed))
+	}
+}
+
+// allowedMethods returns, sorted, the methods of every route matching path.
+func allowedMethods(routes gin.RoutesInfo, path string) []string {
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if matchRoutePattern(route.Path, path) {
+			seen[route.Method] = true
+		}
+	}
+	methods := make([]string, 0, len(seen))
+	for method := range seen {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// matchRoutePattern reports whether path fits a gin pattern with :param and
+// *wildcard segments.
+func matchRoutePattern(pattern, path string) bool {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "*") {
+			return true
+		}
+		if i >= len(got) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != got[i] {
+			return false
+		}
+	}
+	return len(want) == len(got)
+}
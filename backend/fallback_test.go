@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnknownPathReturnsJSONNotFound(t *testing.T) {
+	router := newTestRouter(appDeps{})
+	w := performRequest(router, http.MethodGet, "/no/such/route", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if body["code"] != string(codeNotFound) || body["message"] != "no route for /no/such/route" {
+		t.Fatalf("unexpected error body %v", body)
+	}
+}
+
+func TestWrongMethodReturnsJSONMethodNotAllowed(t *testing.T) {
+	router := newTestRouter(appDeps{})
+	cases := []struct {
+		method, path, allow string
+	}{
+		{method: http.MethodGet, path: "/compute_batch", allow: "POST"},
+		{method: http.MethodGet, path: "/v1/compute_all", allow: "POST"},
+		{method: http.MethodPut, path: "/models/mesh.obj", allow: "DELETE"},
+		{method: http.MethodDelete, path: "/compute", allow: "GET, POST"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := performRequest(router, tc.method, tc.path, "")
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("expected status 405, got %d", w.Code)
+			}
+			if allow := w.Header().Get("Allow"); allow != tc.allow {
+				t.Fatalf("expected Allow %q, got %q", tc.allow, allow)
+			}
+			if body := decodeJSONBody(t, w); body["code"] != string(codeMethodNotAllowed) {
+				t.Fatalf("expected code %s, got %v", codeMethodNotAllowed, body["code"])
+			}
+		})
+	}
+}
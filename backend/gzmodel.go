@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// gzipModelExt marks compressed models, such as mesh.obj.gz. The extension
+// before it names the format the engine sees once the model is decompressed.
+const gzipModelExt = ".gz"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// defaultDecompressedModelBytes caps how far a gzip model is inflated when
+// MAX_MODEL_BYTES is unset, so a small upload cannot fill the disk.
+const defaultDecompressedModelBytes = 1 << 30
+
+// decompressedModelLimit is the most a gzip model may inflate to.
+func (s *server) decompressedModelLimit() int64 {
+	if s.cfg.maxModelBytes > 0 {
+		return s.cfg.maxModelBytes
+	}
+	return defaultDecompressedModelBytes
+}
+
+// trimGzipExt strips a trailing .gz, compared case-insensitively, from name.
+func trimGzipExt(name string) string {
+	if strings.EqualFold(filepath.Ext(name), gzipModelExt) {
+		return name[:len(name)-len(gzipModelExt)]
+	}
+	return name
+}
+
+// isGzipModel reports whether a model is gzip-compressed, either by its name
+// or, for models uploaded under their plain extension, by its magic bytes.
+func isGzipModel(name, path string) bool {
+	if strings.EqualFold(filepath.Ext(name), gzipModelExt) {
+		return true
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(gzipMagic))
+	n, _ := io.ReadFull(f, magic)
+	return bytes.Equal(magic[:n], gzipMagic)
+}
+
+// decompressedModelPath is where the decompressed form of a gzip model is
+// cached: a hidden file next to the original, so GET /models does not list it.
+func decompressedModelPath(gzPath string) string {
+	return filepath.Join(filepath.Dir(gzPath), "."+trimGzipExt(filepath.Base(gzPath)))
+}
+
+// decompressModel returns the uncompressed file for a model. Plain models are
+// used as is; gzip models are decompressed on first use and again whenever
+// the compressed file's mtime changes. The cached copy carries that mtime, so
+// result cache keys still follow the compressed file. Inflating past limit
+// bytes fails with a 413.
+func decompressModel(name, path string, limit int64) (string, error) {
+	if !isGzipModel(name, path) {
+		return path, nil
+	}
+	info, err := statModel(name, path)
+	if err != nil {
+		return "", err
+	}
+	plain := decompressedModelPath(path)
+	if cached, err := os.Stat(plain); err == nil && cached.ModTime().Equal(info.ModTime()) {
+		return plain, nil
+	}
+	if err := gunzipModel(name, path, plain, info.ModTime(), limit); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// gunzipModel decompresses src to dst and stamps dst with modTime. It stops
+// once the output passes limit bytes rather than inflating a gzip bomb.
+func gunzipModel(name, src, dst string, modTime time.Time, limit int64) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return gzipModelError(err)
+	}
+	defer zr.Close()
+
+	// Write then rename so concurrent requests never load a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(zr, limit+1))
+	if err != nil {
+		tmp.Close()
+		return gzipModelError(err)
+	}
+	if n > limit {
+		tmp.Close()
+		return newAPIError(http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("model decompresses to over the %d byte limit", limit)).
+			withDetail("model", name).
+			withDetail("limitBytes", limit)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), modTime, modTime); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// gzipModelError reports corrupt or truncated gzip data as a validation
+// error; other failures, such as a full disk, are returned unchanged.
+func gzipModelError(err error) error {
+	var corrupt flate.CorruptInputError
+	if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &corrupt) {
+		return &validationError{
+			field:   "model",
+			message: "model is not valid gzip data: " + err.Error(),
+		}
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newGzipProject stores data as mesh.obj.gz in a fresh project and returns
+// the router, the model's path and the engine arguments of the last run.
+func newGzipProject(t *testing.T, data []byte) (http.Handler, string, *[]string) {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	gzPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj.gz")
+	if err := os.WriteFile(gzPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var lastArgs []string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			lastArgs, _ = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+	})
+	return router, gzPath, &lastArgs
+}
+
+func TestGzipModelIsDecompressedForTheEngine(t *testing.T) {
+	obj := "v 0 0 0\nv 1 0 0\nv 2 0 0\nv 3 0 0\nv 4 0 0\nf 1 2 3\nf 3 4 5\n"
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(obj))
+	zw.Close()
+	router, gzPath, lastArgs := newGzipProject(t, compressed.Bytes())
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj.gz"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	plain := decompressedModelPath(gzPath)
+	if len(*lastArgs) < 3 || (*lastArgs)[2] != plain {
+		t.Fatalf("expected the engine to load %s, got %v", plain, *lastArgs)
+	}
+	got, err := os.ReadFile(plain)
+	if err != nil || string(got) != obj {
+		t.Fatalf("expected the decompressed model, got %q (%v)", got, err)
+	}
+	gzInfo, _ := os.Stat(gzPath)
+	plainInfo, _ := os.Stat(plain)
+	if !plainInfo.ModTime().Equal(gzInfo.ModTime()) {
+		t.Fatalf("expected the cached copy to carry the compressed file's mtime")
+	}
+}
+
+func TestCorruptGzipModelIsRejected(t *testing.T) {
+	router, _, lastArgs := newGzipProject(t, append([]byte{0x1f, 0x8b}, "not really gzip"...))
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj.gz"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if msg, _ := body["message"].(string); !strings.HasPrefix(msg, "model is not valid gzip data") {
+		t.Fatalf("expected a gzip error message, got %q", msg)
+	}
+	if *lastArgs != nil {
+		t.Fatalf("expected the engine not to run, got %v", *lastArgs)
+	}
+}
+
+func TestGzipModelInflatingPastTheLimitIsRejected(t *testing.T) {
+	t.Setenv("MAX_MODEL_BYTES", "4096")
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(bytes.Repeat([]byte("v 0 0 0\n"), 64<<10))
+	zw.Close()
+	if compressed.Len() > 4096 {
+		t.Fatalf("expected the compressed model to fit the limit, got %d bytes", compressed.Len())
+	}
+	router, gzPath, lastArgs := newGzipProject(t, compressed.Bytes())
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj.gz"}`)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(*lastArgs) != 0 {
+		t.Fatalf("expected the engine not to run, got %v", *lastArgs)
+	}
+	if _, err := os.Stat(decompressedModelPath(gzPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected no decompressed copy to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// engineVersionTimeout bounds the `--version` probe so a wedged engine
+// cannot stall health checks.
+const engineVersionTimeout = 2 * time.Second
+
+// handleLive answers /livez: the process is up and serving. It checks no
+// dependencies, so an orchestrator only restarts a process that has hung.
+func handleLive(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// handleHealth answers /readyz and its older alias /health. It reports ok only
+// when the engine binary exists and is executable and the model directory can
+// be listed. The engine version is included when `--version` answers.
+func (s *server) handleHealth(c *gin.Context) {
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "degraded",
+			"reason": "failed to determine project root",
+		})
+		return
+	}
+
+	enginePath := resolveEnginePath(s.cfg.enginePath, projectRoot)
+	if err := checkEngineExecutable(enginePath); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "degraded",
+			"reason": "engine unavailable: " + err.Error(),
+		})
+		return
+	}
+
+	if err := checkModelDir(s.modelDir(projectRoot)); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "degraded",
+			"reason": "data dir unavailable: " + err.Error(),
+		})
+		return
+	}
+
+	body := gin.H{"status": "ok"}
+	if version := s.probeEngineVersion(c.Request.Context(), projectRoot, enginePath); version != "" {
+		body["engineVersion"] = version
+	}
+	c.JSON(http.StatusOK, body)
+}
+
+// checkModelDir reports why dir cannot serve models, if so.
+func checkModelDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// probeEngineVersion returns the first line printed by `engine --version`,
+// or "" if the engine does not support the flag.
+func (s *server) probeEngineVersion(parent context.Context, projectRoot, enginePath string) string {
+	ctx, cancel := context.WithTimeout(parent, engineVersionTimeout)
+	defer cancel()
+
+	output, err := s.deps.runEngine(ctx, projectRoot, enginePath, "--version")
+	if err != nil {
+		return ""
+	}
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(version)
+}
+
+// unknownEngineVersion is reported when the engine cannot be asked for its
+// version.
+const unknownEngineVersion = "unknown"
+
+// cachedEngineVersion probes the engine version on first use and returns the
+// same answer for the lifetime of the server. main calls it at startup so
+// requests never wait on the probe.
+func (s *server) cachedEngineVersion() string {
+	s.versionOnce.Do(func() {
+		s.engineVersion = s.loadEngineVersion()
+	})
+	return s.engineVersion
+}
+
+func (s *server) loadEngineVersion() string {
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		s.deps.logger.Warn("engine version unavailable", "error", err.Error())
+		return unknownEngineVersion
+	}
+	enginePath := resolveEnginePath(s.cfg.enginePath, projectRoot)
+	version := s.probeEngineVersion(context.Background(), projectRoot, enginePath)
+	if version == "" {
+		s.deps.logger.Warn("engine version unavailable", "engine", enginePath)
+		return unknownEngineVersion
+	}
+	return version
+}
+
+// handleEngineVersion serves the engine build cached at startup.
+func (s *server) handleEngineVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"version": s.cachedEngineVersion()})
+}
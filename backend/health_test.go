@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestEngine writes an executable placeholder engine binary under root.
+func newTestEngine(t *testing.T, root string) string {
+	t.Helper()
+	enginePath := filepath.Join(root, "main")
+	if err := os.WriteFile(enginePath, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake engine: %v", err)
+	}
+	return enginePath
+}
+
+func TestHealthEndpointReturnsOk(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 1)
+	enginePath := newTestEngine(t, projectRoot)
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePathArg string, args ...string) ([]byte, error) {
+			if enginePathArg != enginePath || strings.Join(args, " ") != "--version" {
+				t.Fatalf("health should only probe the engine version, got %s %v", enginePathArg, args)
+			}
+			return []byte("geodesic_engine 1.4.0\n"), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			t.Fatalf("readFile should not be called for health endpoint")
+			return nil, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/health", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	body := decodeJSONBody(t, w)
+	if got, ok := body["status"].(string); !ok || got != "ok" {
+		t.Fatalf("expected status=ok, got %#v", body["status"])
+	}
+	if got := body["engineVersion"]; got != "geodesic_engine 1.4.0" {
+		t.Fatalf("expected engine version, got %#v", got)
+	}
+}
+
+func TestHealthOmitsVersionWhenEngineDoesNotReportOne(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 1)
+	newTestEngine(t, projectRoot)
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return []byte("Usage: ./main <start_id> <end_id> <model_path> [mode]"), errors.New("exit status 1")
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/health", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if _, ok := decodeJSONBody(t, w)["engineVersion"]; ok {
+		t.Fatalf("expected no engineVersion when --version fails")
+	}
+}
+
+func TestHealthReportsDegradedWhenEngineMissing(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return t.TempDir(), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			t.Fatalf("runEngine should not be called when the engine binary is missing")
+			return nil, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/health", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if body["status"] != "degraded" {
+		t.Fatalf("expected status=degraded, got %#v", body["status"])
+	}
+	if reason, _ := body["reason"].(string); !strings.HasPrefix(reason, "engine unavailable") {
+		t.Fatalf("expected engine-unavailable reason, got %#v", body["reason"])
+	}
+}
+
+func TestEngineVersionIsProbedOnceAndCached(t *testing.T) {
+	projectRoot := t.TempDir()
+	newTestEngine(t, projectRoot)
+
+	calls := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			calls++
+			if strings.Join(args, " ") != "--version" {
+				t.Fatalf("expected a --version probe, got %v", args)
+			}
+			return []byte("  geodesic_engine 2.0.1\n"), nil
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		w := performRequest(router, http.MethodGet, "/engine/version", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if got := decodeJSONBody(t, w)["version"]; got != "geodesic_engine 2.0.1" {
+			t.Fatalf("expected trimmed engine version, got %#v", got)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected the engine to be probed once, got %d calls", calls)
+	}
+}
+
+func TestEngineVersionReportsUnknownWhenProbeFails(t *testing.T) {
+	projectRoot := t.TempDir()
+	newTestEngine(t, projectRoot)
+
+	var logs bytes.Buffer
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return []byte("unknown option --version"), errors.New("exit status 1")
+		},
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	w := performRequest(router, http.MethodGet, "/engine/version", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := decodeJSONBody(t, w)["version"]; got != "unknown" {
+		t.Fatalf("expected unknown version, got %#v", got)
+	}
+	if !strings.Contains(logs.String(), "engine version unavailable") {
+		t.Fatalf("expected a warning to be logged, got %q", logs.String())
+	}
+}
+
+func TestLivenessAndReadinessProbes(t *testing.T) {
+	cases := []struct {
+		name      string
+		project   func(t *testing.T) string
+		readyCode int
+		reason    string
+	}{
+		{
+			name: "ready",
+			project: func(t *testing.T) string {
+				root := newTestProject(t, "mesh.obj", 1)
+				newTestEngine(t, root)
+				return root
+			},
+			readyCode: http.StatusOK,
+		},
+		{
+			name:      "engine missing",
+			project:   func(t *testing.T) string { return newTestProject(t, "mesh.obj", 1) },
+			readyCode: http.StatusServiceUnavailable,
+			reason:    "engine unavailable",
+		},
+		{
+			name: "data dir missing",
+			project: func(t *testing.T) string {
+				root := t.TempDir()
+				newTestEngine(t, root)
+				return root
+			},
+			readyCode: http.StatusServiceUnavailable,
+			reason:    "data dir unavailable",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectRoot := tc.project(t)
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					return nil, nil
+				},
+			})
+
+			if w := performRequest(router, http.MethodGet, "/livez", ""); w.Code != http.StatusOK {
+				t.Fatalf("expected /livez to stay 200, got %d", w.Code)
+			}
+			for _, path := range []string{"/readyz", "/health"} {
+				w := performRequest(router, http.MethodGet, path, "")
+				if w.Code != tc.readyCode {
+					t.Fatalf("%s: expected status %d, got %d: %s", path, tc.readyCode, w.Code, w.Body.String())
+				}
+				if reason, _ := decodeJSONBody(t, w)["reason"].(string); !strings.HasPrefix(reason, tc.reason) {
+					t.Fatalf("%s: expected reason %q, got %q", path, tc.reason, reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHistoryFileName = "history.jsonl"
+	defaultHistoryLimit    = 50
+	maxHistoryLimit        = 1000
+)
+
+// historyEntry is one line of the JSON-lines history file.
+type historyEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Model      string    `json:"model"`
+	Start      int       `json:"start"`
+	End        int       `json:"end"`
+	Mode       string    `json:"mode"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// historyLog appends computed routes to a JSON-lines file. The mutex keeps
+// concurrent requests from interleaving partial lines.
+type historyLog struct {
+	mu sync.Mutex
+}
+
+// historyPath returns HISTORY_FILE (relative values are taken from the project
+// root), defaulting to history.jsonl inside the model directory.
+func (s *server) historyPath(projectRoot string) string {
+	if s.cfg.historyFile == "" {
+		return filepath.Join(s.modelDir(projectRoot), defaultHistoryFileName)
+	}
+	if filepath.IsAbs(s.cfg.historyFile) {
+		return filepath.Clean(s.cfg.historyFile)
+	}
+	return filepath.Join(projectRoot, s.cfg.historyFile)
+}
+
+func (h *historyLog) append(path string, entry historyEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recent returns up to limit entries, newest first. Malformed lines are
+// skipped and a missing file is an empty history.
+func (h *historyLog) recent(path string, limit int) ([]historyEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []historyEntry{}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+		if len(entries) > limit {
+			entries = entries[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// recordHistory logs a successful computation. Failures to write are logged
+// but never fail the request.
+func (s *server) recordHistory(job engineJob, elapsed time.Duration) {
+	entry := historyEntry{
+		Timestamp:  time.Now().UTC(),
+		Model:      job.model,
+		Start:      job.start,
+		End:        job.end,
+		Mode:       job.mode,
+		DurationMs: elapsed.Milliseconds(),
+	}
+	if err := s.history.append(s.historyPath(job.projectRoot), entry); err != nil {
+		s.deps.logger.Warn("failed to record history", "request_id", job.requestID, "error", err.Error())
+	}
+}
+
+func (s *server) handleHistory(c *gin.Context) {
+	limit := defaultHistoryLimit
+	if raw, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			respondError(c, newAPIError(400, codeInvalidInput,
+				fmt.Sprintf("limit must be an integer between 1 and %d, got %q", maxHistoryLimit, raw)))
+			return
+		}
+		limit = n
+	}
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+
+	entries, err := s.history.recent(s.historyPath(projectRoot), limit)
+	if err != nil {
+		respondError(c, newAPIError(500, codeInternal, "failed to read history").wrap(err))
+		return
+	}
+	c.JSON(200, entries)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newHistoryRouter(t *testing.T, projectRoot string) http.Handler {
+	t.Helper()
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+}
+
+func decodeHistory(t *testing.T, body []byte) []historyEntry {
+	t.Helper()
+	var entries []historyEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		t.Fatalf("failed to decode history: %v (%s)", err, body)
+	}
+	return entries
+}
+
+func TestHistoryRecordsComputedRoutes(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newHistoryRouter(t, projectRoot)
+
+	performRequest(router, http.MethodPost, "/compute", `{"start":1,"end":2,"model":"mesh.obj"}`)
+	performRequest(router, http.MethodPost, "/heat", `{"start":3,"end":4,"model":"mesh.obj"}`)
+	// Failed requests are not recorded.
+	performRequest(router, http.MethodPost, "/compute", `{"start":1,"end":99,"model":"mesh.obj"}`)
+
+	w := performRequest(router, http.MethodGet, "/history", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	entries := decodeHistory(t, w.Body.Bytes())
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, got %d (%s)", len(entries), w.Body.String())
+	}
+	newest, oldest := entries[0], entries[1]
+	if newest.Mode != "heat" || newest.Start != 3 || newest.End != 4 || newest.Model != "mesh.obj" {
+		t.Fatalf("unexpected newest entry %+v", newest)
+	}
+	if oldest.Mode != "dijkstra" || oldest.Start != 1 || oldest.End != 2 {
+		t.Fatalf("unexpected oldest entry %+v", oldest)
+	}
+	if newest.Timestamp.IsZero() {
+		t.Fatalf("expected a timestamp on history entries")
+	}
+
+	if _, err := os.Stat(filepath.Join(projectRoot, "frontend", "public", "data", "history.jsonl")); err != nil {
+		t.Fatalf("expected history in the data dir by default: %v", err)
+	}
+}
+
+func TestHistoryLimitAndFileOverride(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "routes.jsonl")
+	t.Setenv("HISTORY_FILE", historyFile)
+	router := newHistoryRouter(t, newTestProject(t, "mesh.obj", 10))
+
+	for end := 1; end <= 5; end++ {
+		performRequest(router, http.MethodPost, "/compute", fmt.Sprintf(`{"start":0,"end":%d,"model":"mesh.obj"}`, end))
+	}
+
+	w := performRequest(router, http.MethodGet, "/history?limit=2", "")
+	entries := decodeHistory(t, w.Body.Bytes())
+	if len(entries) != 2 || entries[0].End != 5 || entries[1].End != 4 {
+		t.Fatalf("expected the 2 most recent entries, got %+v", entries)
+	}
+
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		t.Fatalf("expected HISTORY_FILE to be written: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 5 {
+		t.Fatalf("expected 5 history lines, got %d", lines)
+	}
+
+	if w := performRequest(router, http.MethodGet, "/history?limit=zero", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a bad limit, got %d", w.Code)
+	}
+}
+
+func TestHistoryAppendsDoNotInterleave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	h := &historyLog{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.append(path, historyEntry{Model: strings.Repeat("m", 512), Start: i}); err != nil {
+				t.Errorf("append failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := h.recent(path, maxHistoryLimit)
+	if err != nil {
+		t.Fatalf("recent failed: %v", err)
+	}
+	if len(entries) != 50 {
+		t.Fatalf("expected 50 intact entries, got %d", len(entries))
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	defaultIdempotencyTTL    = 10 * time.Minute
+
+	// idempotencySweepInterval is how often expired responses are dropped.
+	idempotencySweepInterval = time.Minute
+)
+
+// storedResponse is a finished response kept for replay.
+type storedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// idempotencyEntry tracks one Idempotency-Key. done is closed once the first
+// request with the key has finished; response is then set if it was kept.
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	response    *storedResponse
+	expires     time.Time
+}
+
+// idempotencyStore holds in-flight and finished requests by Idempotency-Key,
+// keeping finished responses for ttl.
+type idempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+// begin returns the entry for key. owner is true when there was none, in
+// which case the caller must run the request and then call finish.
+func (st *idempotencyStore) begin(key string, fingerprint [sha256.Size]byte) (entry *idempotencyEntry, owner bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := st.now()
+	if now.Sub(st.lastSweep) >= idempotencySweepInterval {
+		st.sweep(now)
+	}
+	if e, ok := st.entries[key]; ok && (e.response == nil || now.Before(e.expires)) {
+		return e, false
+	}
+	e := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	st.entries[key] = e
+	return e, true
+}
+
+// finish releases the callers waiting on entry. A nil response forgets the
+// key, so the next request with it runs afresh.
+func (st *idempotencyStore) finish(key string, entry *idempotencyEntry, response *storedResponse) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if response != nil {
+		entry.response = response
+		entry.expires = st.now().Add(st.ttl)
+	} else if st.entries[key] == entry {
+		delete(st.entries, key)
+	}
+	close(entry.done)
+}
+
+func (st *idempotencyStore) sweep(now time.Time) {
+	for key, e := range st.entries {
+		if e.response != nil && !now.Before(e.expires) {
+			delete(st.entries, key)
+		}
+	}
+	st.lastSweep = now
+}
+
+// recordingWriter copies the response body so it can be stored for replay.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotency replays the stored response to a POST that repeats an earlier
+// request's Idempotency-Key, so a client retrying after a network timeout
+// does not run the engine twice. A retry that arrives while the first request
+// is still running waits for it. Responses with a 5xx status are not kept,
+// and reusing a key for a different request is rejected with 422.
+func (s *server) idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respondError(c, newAPIError(http.StatusBadRequest, codeInvalidInput,
+				fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)))
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, errRequestBody(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		// Accept picks JSON or CSV, so it is part of the request. The
+		// encoding is not: replays negotiate it afresh.
+		fingerprint := sha256.Sum256(append([]byte(c.Request.URL.RequestURI()+"\x00"+c.GetHeader("Accept")+"\x00"), body...))
+
+		for {
+			entry, owner := s.idempotent.begin(key, fingerprint)
+			if entry.fingerprint != fingerprint {
+				respondError(c, newAPIError(http.StatusUnprocessableEntity, codeKeyReused,
+					idempotencyKeyHeader+" was already used for a different request"))
+				return
+			}
+			if owner {
+				s.recordIdempotent(c, key, entry)
+				return
+			}
+			select {
+			case <-entry.done:
+			case <-c.Request.Context().Done():
+				respondError(c, errCanceled(c.Request.Context().Err()))
+				return
+			}
+			if entry.response != nil {
+				replayResponse(c, entry.response)
+				return
+			}
+			// The first request failed and was not kept; run this one.
+		}
+	}
+}
+
+func (s *server) recordIdempotent(c *gin.Context, key string, entry *idempotencyEntry) {
+	var stored *storedResponse
+	// Deferred so a panicking handler still releases the waiters.
+	defer func() { s.idempotent.finish(key, entry, stored) }()
+
+	w := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	if !storableStatus(w.Status()) {
+		return
+	}
+	header := w.Header().Clone()
+	body := bytes.Clone(w.body.Bytes())
+	// Keep the identity body so a retry without gzip support can read it.
+	if header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return
+		}
+		header.Del("Content-Encoding")
+		header.Del("Content-Length")
+	}
+	stored = &storedResponse{status: w.Status(), header: header, body: body}
+}
+
+// storableStatus reports whether a response would come out the same if the
+// request ran again, and so may be replayed: successes and client errors,
+// but not a canceled request (499), a timeout (408) or a rate-limit
+// rejection (429), which a retry can get past.
+func storableStatus(status int) bool {
+	switch status {
+	case statusClientClosedRequest, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return (status >= 200 && status < 300) || (status >= 400 && status < 500)
+}
+
+// replayResponse writes a stored response, keeping this request's own ID and
+// compressing it only if this request accepts gzip.
+func replayResponse(c *gin.Context, response *storedResponse) {
+	header := c.Writer.Header()
+	for name, values := range response.header {
+		if name != http.CanonicalHeaderKey(requestIDHeader) {
+			header[name] = slices.Clone(values)
+		}
+	}
+	header.Set(idempotentReplayedHeader, "true")
+	body := response.body
+	if compressed, ok := gzipFor(c.GetHeader("Accept-Encoding"), body); ok {
+		header.Set("Content-Encoding", "gzip")
+		body = compressed
+	}
+	c.Writer.WriteHeader(response.status)
+	c.Writer.Write(body)
+	c.Abort()
+}
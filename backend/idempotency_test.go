@@ -0,0 +1,174 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postWithIdempotencyKey(router http.Handler, path, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRetriedRequestReplaysStoredResult(t *testing.T) {
+	// Without the result cache only the idempotency store can avoid a rerun.
+	t.Setenv("CACHE_SIZE", "0")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(fmt.Sprintf(`{"path":[0,%d,4]}`, runs)), nil
+		},
+	})
+
+	body := `{"start":0,"end":4,"model":"mesh.obj"}`
+	first := postWithIdempotencyKey(router, "/compute", "retry-1", body)
+	retry := postWithIdempotencyKey(router, "/compute", "retry-1", body)
+	if first.Code != http.StatusOK || retry.Code != http.StatusOK {
+		t.Fatalf("expected both attempts to succeed, got %d and %d", first.Code, retry.Code)
+	}
+	if runs != 1 {
+		t.Fatalf("expected the engine to run once, ran %d times", runs)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Fatalf("expected the retry to get the first result %s, got %s", first.Body.String(), retry.Body.String())
+	}
+	if retry.Header().Get(idempotentReplayedHeader) != "true" || first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("expected only the retry to be marked as replayed")
+	}
+	if retry.Header().Get(requestIDHeader) == first.Header().Get(requestIDHeader) {
+		t.Fatalf("expected the retry to keep its own request ID")
+	}
+
+	if w := postWithIdempotencyKey(router, "/compute", "retry-2", body); w.Code != http.StatusOK || runs != 2 {
+		t.Fatalf("expected a new key to run the engine again, got %d after %d runs", w.Code, runs)
+	}
+}
+
+func TestIdempotencyKeyReusedForDifferentRequestIsRejected(t *testing.T) {
+	router := newAPIKeyRouter(t)
+	postWithIdempotencyKey(router, "/compute", "reused", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	w := postWithIdempotencyKey(router, "/compute", "reused", `{"start":0,"end":5,"model":"mesh.obj"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := decodeJSONBody(t, w); body["code"] != string(codeKeyReused) {
+		t.Fatalf("expected code %s, got %v", codeKeyReused, body["code"])
+	}
+}
+
+func TestFailedRequestIsNotReplayed(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			runs++
+			if runs == 1 {
+				return []byte("out of memory"), errors.New("exit status 1")
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+	})
+
+	body := `{"start":0,"end":4,"model":"mesh.obj"}`
+	if w := postWithIdempotencyKey(router, "/compute", "flaky", body); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected the first attempt to fail, got %d", w.Code)
+	}
+	w := postWithIdempotencyKey(router, "/compute", "flaky", body)
+	if w.Code != http.StatusOK || w.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("expected the retry to run afresh and succeed, got %d", w.Code)
+	}
+	if runs != 2 {
+		t.Fatalf("expected the engine to run twice, ran %d times", runs)
+	}
+}
+
+func TestOnlyRepeatableResponsesAreStored(t *testing.T) {
+	for status, want := range map[int]bool{
+		http.StatusOK:                  true,
+		http.StatusCreated:             true,
+		http.StatusBadRequest:          true,
+		http.StatusNotFound:            true,
+		http.StatusUnprocessableEntity: true,
+		http.StatusNotModified:         false,
+		http.StatusRequestTimeout:      false,
+		http.StatusTooManyRequests:     false,
+		statusClientClosedRequest:      false,
+		http.StatusInternalServerError: false,
+		http.StatusServiceUnavailable:  false,
+	} {
+		if got := storableStatus(status); got != want {
+			t.Errorf("storableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestReplayNegotiatesItsOwnEncoding(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	// Large enough to be compressed for clients that accept gzip.
+	payload := fmt.Sprintf(`{"path":[0,4],"pad":%q}`, strings.Repeat("x", 2*gzipMinBytes))
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			runs++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(payload), nil },
+	})
+	post := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/compute", strings.NewReader(`{"start":0,"end":4,"model":"mesh.obj"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(idempotencyKeyHeader, "encoding-1")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post(map[string]string{"Accept-Encoding": "gzip"})
+	if first.Code != http.StatusOK || first.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzipped first response, got %d %q", first.Code, first.Header().Get("Content-Encoding"))
+	}
+
+	plain := post(nil)
+	if plain.Header().Get(idempotentReplayedHeader) != "true" || runs != 1 {
+		t.Fatalf("expected a replay without another run, got %d runs", runs)
+	}
+	if plain.Header().Get("Content-Encoding") != "" || plain.Body.String() != payload {
+		t.Fatalf("expected the identity body for a retry without gzip, got %q encoding", plain.Header().Get("Content-Encoding"))
+	}
+
+	gzipped := post(map[string]string{"Accept-Encoding": "gzip"})
+	zr, err := gzip.NewReader(gzipped.Body)
+	if err != nil || gzipped.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzipped replay, got %q (%v)", gzipped.Header().Get("Content-Encoding"), err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != payload {
+		t.Fatalf("expected the gzipped replay to inflate to the payload")
+	}
+
+	if w := post(map[string]string{"Accept": "text/csv"}); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a key reused for CSV to be rejected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,273 @@
+//go:build integration
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// These tests run the real engine named by ENGINE_PATH:
+//
+//	ENGINE_PATH=../main go test -tags integration ./...
+//
+// They are skipped when ENGINE_PATH is unset or not executable.
+
+// newIntegrationServer serves the API from a fresh project that runs the
+// engine at ENGINE_PATH.
+func newIntegrationServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	enginePath := os.Getenv("ENGINE_PATH")
+	if enginePath == "" {
+		t.Skip("ENGINE_PATH is not set")
+	}
+	enginePath, err := filepath.Abs(enginePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEngineExecutable(enginePath); err != nil {
+		t.Skipf("engine unavailable: %v", err)
+	}
+	// The project root is a temp dir, so a relative path would resolve there.
+	t.Setenv("ENGINE_PATH", enginePath)
+
+	projectRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectRoot, "frontend", "public", "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := newServer(appDeps{resolveProjectRoot: func() (string, error) { return projectRoot, nil }})
+	ts := httptest.NewServer(s.router())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// uploadModel stores an OBJ model through POST /v1/models.
+func uploadModel(t *testing.T, ts *httptest.Server, name, obj string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(obj))
+	form.Close()
+
+	resp, err := http.Post(ts.URL+"/v1/models", form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected the upload to succeed, got %d", resp.StatusCode)
+	}
+}
+
+func TestEngineComputesKnownShortestPath(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	resp, err := http.Post(ts.URL+"/v1/compute", "application/json",
+		bytes.NewReader([]byte(`{"start":0,"end":2,"model":"square.obj"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	var result struct {
+		Path []int `json:"path"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode the engine result: %v", err)
+	}
+	if !slices.Equal(result.Path, selfTestPath) {
+		t.Fatalf("expected the diagonal %v, got %v", selfTestPath, result.Path)
+	}
+}
+
+func TestEngineSelfTestPasses(t *testing.T) {
+	ts := newIntegrationServer(t)
+
+	resp, err := http.Get(ts.URL + "/selftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the self-test to pass, got %d", resp.StatusCode)
+	}
+}
+
+// postEngine posts body to path and decodes the 200 response into out.
+func postEngine(t *testing.T, ts *httptest.Server, path, body string, out any) {
+	t.Helper()
+	resp, err := http.Post(ts.URL+path, "application/json", bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure bytes.Buffer
+		failure.ReadFrom(resp.Body)
+		t.Fatalf("%s: expected status 200, got %d: %s", path, resp.StatusCode, failure.String())
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("%s: failed to decode the engine result: %v", path, err)
+	}
+}
+
+type integrationPath struct {
+	Path          []int   `json:"path"`
+	TotalDistance float64 `json:"totalDistance"`
+	Reachable     bool    `json:"reachable"`
+}
+
+func TestEnginePathModes(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	tests := []struct {
+		path, body string
+		want       []int
+		distance   float64
+	}{
+		{"/v1/compute_astar_path", `{"start":0,"end":2,"model":"square.obj"}`, selfTestPath, math.Sqrt2},
+		{"/v1/compute_astar_path", `{"start":0,"end":2,"model":"square.obj","heuristic":"manhattan"}`, selfTestPath, math.Sqrt2},
+		{"/v1/compute_bellman_ford_path", `{"start":0,"end":2,"model":"square.obj"}`, selfTestPath, math.Sqrt2},
+		// BFS counts hops, so the diagonal is 1.
+		{"/v1/compute_bfs_path", `{"start":0,"end":2,"model":"square.obj"}`, selfTestPath, 1},
+		// The faces are wound 0→1→2 and 0→2→3, so 1→0 directed goes via 2.
+		{"/v1/compute", `{"start":1,"end":0,"model":"square.obj","directed":true}`, []int{1, 2, 0}, 1 + math.Sqrt2},
+	}
+	for _, tt := range tests {
+		var result integrationPath
+		postEngine(t, ts, tt.path, tt.body, &result)
+		if !result.Reachable || !slices.Equal(result.Path, tt.want) || math.Abs(result.TotalDistance-tt.distance) > 1e-4 {
+			t.Errorf("%s %s: expected %v (%v), got %+v", tt.path, tt.body, tt.want, tt.distance, result)
+		}
+	}
+}
+
+func TestEngineKShortestPaths(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	var paths []integrationPath
+	postEngine(t, ts, "/v1/compute_ksp", `{"start":0,"end":2,"model":"square.obj","k":3}`, &paths)
+	if len(paths) != 3 || !slices.Equal(paths[0].Path, selfTestPath) {
+		t.Fatalf("expected the diagonal then both sides, got %+v", paths)
+	}
+	for _, p := range paths[1:] {
+		if len(p.Path) != 3 || math.Abs(p.TotalDistance-2) > 1e-4 {
+			t.Fatalf("expected the two sides of length 2, got %+v", paths)
+		}
+	}
+}
+
+func TestEngineWholeGraphModes(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	var mst struct {
+		TotalWeight float64 `json:"totalWeight"`
+		Edges       []struct {
+			Source, Target int
+		} `json:"edges"`
+	}
+	postEngine(t, ts, "/v1/compute_mst", `{"model":"square.obj"}`, &mst)
+	if len(mst.Edges) != 3 || math.Abs(mst.TotalWeight-3) > 1e-4 {
+		t.Fatalf("expected three unit sides, got %+v", mst)
+	}
+
+	var diameter struct {
+		Diameter  float64 `json:"diameter"`
+		Endpoints []int   `json:"endpoints"`
+	}
+	postEngine(t, ts, "/v1/compute_diameter", `{"model":"square.obj"}`, &diameter)
+	slices.Sort(diameter.Endpoints)
+	if math.Abs(diameter.Diameter-2) > 1e-4 || !slices.Equal(diameter.Endpoints, []int{1, 3}) {
+		t.Fatalf("expected the off-diagonal corners 2 apart, got %+v", diameter)
+	}
+}
+
+func TestEngineWeightedAnalytics(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	var analytics struct {
+		GraphMetrics struct {
+			Weighted   bool    `json:"weighted"`
+			PathLength float64 `json:"pathLength"`
+		} `json:"graphMetrics"`
+	}
+	postEngine(t, ts, "/v1/analytics", `{"start":0,"end":2,"model":"square.obj","weighted":true}`, &analytics)
+	if !analytics.GraphMetrics.Weighted || math.Abs(analytics.GraphMetrics.PathLength-math.Sqrt2) > 1e-4 {
+		t.Fatalf("expected edge-length metrics, got %+v", analytics.GraphMetrics)
+	}
+
+	postEngine(t, ts, "/v1/analytics", `{"start":0,"end":2,"model":"square.obj"}`, &analytics)
+	if analytics.GraphMetrics.Weighted || analytics.GraphMetrics.PathLength != 1 {
+		t.Fatalf("expected hop-count metrics, got %+v", analytics.GraphMetrics)
+	}
+}
+
+func TestEngineAcceptsHeatParameters(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "square.obj", selfTestModel)
+
+	var heat struct {
+		Curves []any `json:"curves"`
+	}
+	postEngine(t, ts, "/v1/heat", `{"start":0,"end":2,"model":"square.obj","radius":2,"weight":0.5}`, &heat)
+	if len(heat.Curves) == 0 {
+		t.Fatal("expected a heat curve")
+	}
+}
+
+func TestEngineReportsUnreachableTarget(t *testing.T) {
+	ts := newIntegrationServer(t)
+	uploadModel(t, ts, "apart.obj", "v 0 0 0\nv 1 0 0\nv 0 1 0\nv 5 0 0\nv 6 0 0\nv 5 1 0\nf 1 2 3\nf 4 5 6\n")
+
+	resp, err := http.Post(ts.URL+"/v1/compute", "application/json",
+		bytes.NewReader([]byte(`{"start":0,"end":4,"model":"apart.obj"}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result integrationPath
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode the engine result: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Reachable || len(result.Path) != 0 {
+		t.Fatalf("expected 200 with an unreachable result, got %d %+v", resp.StatusCode, result)
+	}
+	if got := resp.Header.Get("Warning"); got != noPathWarning {
+		t.Fatalf("expected Warning %q, got %q", noPathWarning, got)
+	}
+}
+
+func TestEngineUsesCSVEdgeWeights(t *testing.T) {
+	ts := newIntegrationServer(t)
+	// The direct 0-2 edge is cheapest by weight.
+	uploadModel(t, ts, "graph.csv", "source,target,weight\n0,1,5\n1,2,5\n0,2,1\n")
+
+	var result integrationPath
+	postEngine(t, ts, "/v1/compute", `{"start":0,"end":2,"model":"graph.csv"}`, &result)
+	if !slices.Equal(result.Path, []int{0, 2}) || result.TotalDistance != 1 {
+		t.Fatalf("expected the weight-1 edge, got %+v", result)
+	}
+	postEngine(t, ts, "/v1/compute_astar_path", `{"start":0,"end":2,"model":"graph.csv"}`, &result)
+	if result.TotalDistance != 1 {
+		t.Fatalf("expected A* to honour the weights too, got %+v", result)
+	}
+}
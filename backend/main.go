@@ -1,45 +1,112 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/sync/singleflight"
 )
 
 type computeRequest struct {
-	Start int    `json:"start"`
-	End   int    `json:"end"`
+	// Start and End are pointers so an omitted field can be told apart from
+	// an explicit 0.
+	Start *int   `json:"start"`
+	End   *int   `json:"end"`
 	Model string `json:"model"`
+
+	// Heuristic selects the A* distance estimate; ignored by other modes.
+	Heuristic string `json:"heuristic,omitempty"`
+	// K is how many paths the k-shortest-paths mode returns.
+	K *int `json:"k,omitempty"`
+	// Radius and Weight tune the heat kernel; see heatArgs.
+	Radius *float64 `json:"radius,omitempty"`
+	Weight *float64 `json:"weight,omitempty"`
+	// Directed treats the model's edges as one-way; see directedFlag.
+	Directed bool `json:"directed,omitempty"`
+	// Weighted uses edge weights in analytics; see weightedFlag.
+	Weighted bool `json:"weighted,omitempty"`
+	// Engine names an entry of ENGINES to run instead of the default binary.
+	Engine string `json:"engine,omitempty"`
+
+	// StartCoord and EndCoord, when set, replace Start and End with the
+	// nearest vertex to the given point.
+	StartCoord *coordinate `json:"startCoord,omitempty"`
+	EndCoord   *coordinate `json:"endCoord,omitempty"`
 }
 
+// extraArgsFunc derives mode-specific engine arguments that follow the mode
+// name. A returned error is reported to the client as a bad request.
+type extraArgsFunc func(req computeRequest) ([]string, error)
+
 type appDeps struct {
 	resolveProjectRoot func() (string, error)
-	runEngine          func(projectRoot, enginePath string, args ...string) ([]byte, error)
+	runEngine          func(ctx context.Context, workDir, enginePath string, args ...string) ([]byte, error)
 	readFile           func(path string) ([]byte, error)
+	logger             *slog.Logger
+	// streamEngine runs the engine, calling onLine for each stdout line as it
+	// is printed, and returns stderr.
+	streamEngine func(ctx context.Context, workDir, enginePath string, onLine func(string), args ...string) ([]byte, error)
+	// accessLog receives one line per request in the LOG_FORMAT format.
+	accessLog io.Writer
+	// tracerProvider creates request and engine spans; a no-op by default.
+	tracerProvider trace.TracerProvider
 }
 
-func defaultResolveProjectRoot() (string, error) {
-	if exe, err := os.Executable(); err == nil {
-		exeDir := filepath.Dir(exe)
-		return filepath.Clean(filepath.Join(exeDir, "..")), nil
-	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Clean(filepath.Join(wd, "..")), nil
-}
+type server struct {
+	deps    appDeps
+	cfg     config
+	cache   *resultCache
+	engines *engineTracker
+	// slots is a semaphore bounding concurrent engine subprocesses, and
+	// waiting bounds how many callers may queue for one.
+	slots   chan struct{}
+	waiting chan struct{}
+	metrics *metrics
+	tracer  trace.Tracer
+	history *historyLog
+	graphs  *modelGraphCache
+	remote  *remoteModels
+	// idempotent holds responses by Idempotency-Key for IDEMPOTENCY_TTL.
+	idempotent *idempotencyStore
+	// limiter is nil when RATE_LIMIT=0.
+	limiter *rateLimiter
+	// breaker is nil when BREAKER_THRESHOLD=0.
+	breaker *circuitBreaker
+	// modeDefaults is swapped whole when SIGHUP reloads MODE_DEFAULTS_FILE.
+	modeDefaults atomic.Pointer[map[string]modeParams]
 
-func defaultRunEngine(projectRoot, enginePath string, args ...string) ([]byte, error) {
-	cmd := exec.Command(enginePath, args...)
-	cmd.Dir = projectRoot
-	return cmd.CombinedOutput()
+	// flights collapses concurrent cache misses for the same key into one
+	// engine run.
+	flights singleflight.Group
+	runsMu  sync.Mutex
+	runs    map[string]*sharedRun
+
+	versionOnce   sync.Once
+	engineVersion string
+
+	// stopping is cancelled by POST /shutdown; main serves until it or a
+	// signal ends.
+	stopping context.Context
+	stop     context.CancelFunc
 }
 
 func withDefaultDeps(deps appDeps) appDeps {
@@ -52,91 +119,435 @@ func withDefaultDeps(deps appDeps) appDeps {
 	if deps.readFile == nil {
 		deps.readFile = os.ReadFile
 	}
+	if deps.logger == nil {
+		deps.logger = slog.Default()
+	}
+	if deps.streamEngine == nil {
+		deps.streamEngine = defaultStreamEngine
+	}
+	if deps.accessLog == nil {
+		deps.accessLog = gin.DefaultWriter
+	}
+	if deps.tracerProvider == nil {
+		deps.tracerProvider = noop.NewTracerProvider()
+	}
 	return deps
 }
 
-func registerEngineRoute(r *gin.Engine, deps appDeps, routePath, resultFileName, mode string) {
-	r.POST(routePath, func(c *gin.Context) {
-		var req computeRequest
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(400, gin.H{"error": err.Error()})
-			return
+// engineRoute describes how a request maps onto an engine mode.
+type engineRoute struct {
+	mode string
+	// resultKeys are the top-level keys every result object must carry.
+	resultKeys []string
+	// arrayResult accepts a top-level JSON array as the result.
+	arrayResult bool
+	extraArgs   extraArgsFunc
+	checkResult func(payload []byte) error
+	// paginate lets clients page through an array result with ?offset= and
+	// ?limit=.
+	paginate bool
+	// sameNodePath marks single-path modes, whose start == end answer is
+	// known without running the engine.
+	sameNodePath bool
+	// wholeGraph marks modes that compute over the entire model, such as the
+	// minimum spanning tree, and take no start or end.
+	wholeGraph bool
+	// directed lets requests pass directedFlag; surface modes reject it.
+	directed bool
+	// surface marks modes that need the mesh's faces, which edge-list models
+	// lack.
+	surface bool
+	// weighted lets requests pass weightedFlag, which only analytics reads.
+	weighted bool
+}
+
+// requireEndpoint reports a missing start or end index unless a coordinate
+// was sent in its place.
+func requireEndpoint(field string, index *int, coord *coordinate) *apiError {
+	if index != nil || coord != nil {
+		return nil
+	}
+	return newAPIError(400, codeInvalidInput, fmt.Sprintf("missing required field %q", field)).
+		withDetail("field", field)
+}
+
+// buildJob validates req and assembles the engine invocation for route.
+func (s *server) buildJob(ctx context.Context, requestID, projectRoot string, req computeRequest, route engineRoute) (engineJob, error) {
+	if strings.TrimSpace(req.Model) == "" {
+		return engineJob{}, newAPIError(400, codeInvalidInput, "model is required")
+	}
+	if !route.wholeGraph {
+		if apiErr := requireEndpoint("start", req.Start, req.StartCoord); apiErr != nil {
+			return engineJob{}, apiErr
+		}
+		if apiErr := requireEndpoint("end", req.End, req.EndCoord); apiErr != nil {
+			return engineJob{}, apiErr
 		}
+		if vErr := checkIndexLimit("start", req.Start, s.cfg.maxVertexIndex); vErr != nil {
+			return engineJob{}, vErr
+		}
+		if vErr := checkIndexLimit("end", req.End, s.cfg.maxVertexIndex); vErr != nil {
+			return engineJob{}, vErr
+		}
+	}
+	modelName, modelPath, err := s.locateModel(ctx, projectRoot, req.Model)
+	if err != nil {
+		return engineJob{}, err
+	}
+	if route.surface {
+		if vErr := checkSurfaceModel(modelName, route.mode); vErr != nil {
+			return engineJob{}, vErr
+		}
+	}
+	if err := s.checkModelSize(modelName, modelPath); err != nil {
+		return engineJob{}, err
+	}
+	if modelPath, err = decompressModel(modelName, modelPath, s.decompressedModelLimit()); err != nil {
+		return engineJob{}, err
+	}
+	// A decompressed copy cached under a higher limit is caught here.
+	if err := s.checkModelSize(modelName, modelPath); err != nil {
+		return engineJob{}, err
+	}
+	if err := checkModelNotEmpty(modelName, modelPath); err != nil {
+		return engineJob{}, err
+	}
+	modelPath, err = convertModel(modelName, modelPath)
+	if err != nil {
+		return engineJob{}, err
+	}
 
-		projectRoot, err := deps.resolveProjectRoot()
+	// Whole-graph modes ignore the endpoints, but the engine still reads
+	// them positionally.
+	start, end := 0, 0
+	if !route.wholeGraph {
+		req, err = resolveCoordinates(req, modelName, modelPath)
 		if err != nil {
-			c.JSON(500, gin.H{"error": "failed to determine project root"})
-			return
+			return engineJob{}, err
 		}
+		start, end = *req.Start, *req.End
+		if vErr := s.validateEndpoints(start, end, modelPath); vErr != nil {
+			return engineJob{}, vErr
+		}
+	}
 
-		// Only allow selecting a file name (prevents path traversal like ../../etc/passwd).
-		modelName := filepath.Base(strings.ReplaceAll(req.Model, "\\", "/"))
-		modelPath := filepath.Join(projectRoot, "frontend", "public", "data", modelName)
-		enginePath := filepath.Join(projectRoot, "main")
+	args := []string{fmt.Sprint(start), fmt.Sprint(end), modelPath}
+	if route.mode != "" {
+		args = append(args, route.mode)
+	}
+	if route.extraArgs != nil {
+		extra, err := route.extraArgs(s.withModeDefaults(route.mode, req))
+		if err != nil {
+			return engineJob{}, newAPIError(400, codeInvalidInput, err.Error())
+		}
+		args = append(args, extra...)
+	}
 
-		args := []string{fmt.Sprint(req.Start), fmt.Sprint(req.End), modelPath}
-		if mode != "" {
-			args = append(args, mode)
+	mode := route.mode
+	if mode == "" {
+		mode = "dijkstra"
+	}
+	engine := strings.TrimSpace(req.Engine)
+	if engine != "" {
+		if _, ok := s.cfg.engines[engine]; !ok {
+			return engineJob{}, newAPIError(400, codeInvalidInput, fmt.Sprintf("unknown engine %q", engine)).
+				withDetail("field", "engine").
+				withDetail("available", slices.Sorted(maps.Keys(s.cfg.engines)))
 		}
+	}
+	if req.Directed {
+		if !route.directed {
+			return engineJob{}, newAPIError(400, codeInvalidInput,
+				fmt.Sprintf("directed is not supported by %s mode", mode)).withDetail("field", "directed")
+		}
+		args = append(args, directedFlag)
+	}
+	if req.Weighted {
+		if !route.weighted {
+			return engineJob{}, newAPIError(400, codeInvalidInput,
+				fmt.Sprintf("weighted is not supported by %s mode", mode)).withDetail("field", "weighted")
+		}
+		args = append(args, weightedFlag)
+	}
+	return engineJob{
+		requestID:      requestID,
+		mode:           mode,
+		engine:         engine,
+		start:          start,
+		end:            end,
+		projectRoot:    projectRoot,
+		model:          modelName,
+		modelPath:      modelPath,
+		args:           args,
+		resultFileName: resultFileForMode(route.mode),
+		resultKeys:     route.resultKeys,
+		arrayResult:    route.arrayResult,
+		checkResult:    route.checkResult,
+		sameNodePath:   route.sameNodePath,
+	}, nil
+}
 
-		output, err := deps.runEngine(projectRoot, enginePath, args...)
+// registerEngineRoute serves route at routePath for both a JSON POST body and
+// a GET query string, so results can also be bookmarked and shared by URL.
+func (s *server) registerEngineRoute(r gin.IRoutes, routePath string, route engineRoute) {
+	query := bindComputeQuery
+	if route.wholeGraph {
+		query = bindModelQuery
+	}
+	r.POST(routePath, s.engineHandler(route, bindComputeJSON))
+	r.GET(routePath, s.engineHandler(route, query))
+}
+
+func (s *server) engineHandler(route engineRoute, bind func(*gin.Context) (computeRequest, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := bind(c)
 		if err != nil {
-			msg := strings.TrimSpace(string(output))
-			if msg == "" {
-				msg = err.Error()
+			respondError(c, errRequestBody(err))
+			return
+		}
+		c.Set(ctxComputeRequest, req)
+		var window page
+		if route.paginate {
+			if window, err = parsePage(c); err != nil {
+				respondError(c, err)
+				return
 			}
-			c.JSON(500, gin.H{
-				"error":     msg,
-				"modelPath": modelPath,
-			})
+		}
+		enveloped, err := parseQueryFlag(c, "envelope")
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		withMeta, err := parseQueryFlag(c, "meta")
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		summarized, err := parseQueryFlag(c, "summary")
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if summarized && !slices.Equal(route.resultKeys, pathResultKeys) {
+			respondError(c, newAPIError(400, codeInvalidInput, "summary is only supported by path modes").
+				withDetail("field", "summary"))
 			return
 		}
 
-		resultPath := filepath.Join(projectRoot, "frontend", "public", resultFileName)
-		payload, err := deps.readFile(resultPath)
+		projectRoot, err := s.deps.resolveProjectRoot()
 		if err != nil {
-			c.JSON(500, gin.H{"error": "failed to read " + resultFileName})
+			respondError(c, errProjectRoot(err))
 			return
 		}
 
-		c.Data(200, "application/json", payload)
-	})
+		_, span := s.tracer.Start(c.Request.Context(), "validate")
+		job, err := s.buildJob(c.Request.Context(), requestIDFrom(c), projectRoot, req, route)
+		endSpan(span, err)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(jobAttributes(job)...)
+		if req.StartCoord != nil || req.EndCoord != nil {
+			c.Header(resolvedStartHeader, strconv.Itoa(job.start))
+			c.Header(resolvedEndHeader, strconv.Itoa(job.end))
+		}
+
+		res, err := s.runEngine(c.Request.Context(), job)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setEngineDuration(c, res)
+		setCacheStatus(c, res.cache)
+		if slices.Equal(route.resultKeys, pathResultKeys) && unreachableResult(res.payload) {
+			c.Header("Warning", noPathWarning)
+		}
+		payload := window.apply(c, res.payload)
+		if summarized {
+			if payload, err = summarizePath(payload); err != nil {
+				respondError(c, err)
+				return
+			}
+		}
+		body, contentType := negotiateCSV(c, payload, job.resultFileName)
+		if enveloped && contentType == "application/json" {
+			body = wrapEnvelope(envelopeInputFor(job, route), body)
+		}
+		if withMeta && contentType == "application/json" {
+			body = wrapMeta(s.resultMetaFor(job, res, time.Now()), body, enveloped)
+		}
+
+		if notModified(c, body) {
+			return
+		}
+		writeBody(c, 200, contentType, body)
+	}
 }
 
-func buildRouter(deps appDeps) *gin.Engine {
+func newServer(deps appDeps) *server {
+	cfg := loadConfig()
+	var limiter *rateLimiter
+	if cfg.rateLimit > 0 {
+		limiter = newRateLimiter(cfg.rateLimit, cfg.rateBurst)
+	}
+	var breaker *circuitBreaker
+	if cfg.breakerThreshold > 0 {
+		breaker = newCircuitBreaker(cfg.breakerThreshold, cfg.breakerWindow, cfg.breakerCooldown)
+	}
 	deps = withDefaultDeps(deps)
+	stopping, stop := context.WithCancel(context.Background())
+	s := &server{
+		deps:    deps,
+		cfg:     cfg,
+		cache:   newResultCache(cfg.cacheSize, cfg.cacheTTL),
+		engines: newEngineTracker(),
+		slots:   make(chan struct{}, cfg.maxConcurrency),
+		waiting: make(chan struct{}, cfg.maxQueue),
+		metrics: newMetrics(),
+		tracer:  deps.tracerProvider.Tracer(tracerName),
+		history: &historyLog{},
+		graphs:  newModelGraphCache(),
+		remote:  newRemoteModels(filepath.Join(os.TempDir(), remoteModelDirName), cfg.modelFetchTimeout, cfg.maxUploadBytes, cfg.modelFetchHosts, cfg.modelFetchPrivate),
+		runs:    map[string]*sharedRun{},
+		limiter: limiter,
+		breaker: breaker,
 
-	r := gin.Default()
+		idempotent: newIdempotencyStore(cfg.idempotencyTTL),
+		stopping:   stopping,
+		stop:       stop,
+	}
+	s.metrics.watchCache(s.cache)
+	if err := s.reloadModeDefaults(); err != nil {
+		log.Printf("not loading mode defaults: %v", err)
+	}
+	return s
+}
+
+func buildRouter(deps appDeps) *gin.Engine {
+	return newServer(deps).router()
+}
 
-	// Allow the Vite dev server (and localhost variants) to call this API.
-	r.Use(cors.New(cors.Config{
-		AllowAllOrigins: true,
-		AllowMethods:    []string{"POST", "OPTIONS"},
-		AllowHeaders:    []string{"Content-Type"},
-		MaxAge:          12 * time.Hour,
-	}))
+func (s *server) router() *gin.Engine {
+	r := gin.New()
+	r.Use(s.accessLogger())
+	r.Use(s.requestLogger())
+	r.Use(s.recoverPanics())
+	r.Use(s.metrics.middleware())
+	r.Use(s.tracing())
+	r.Use(s.requestTimeout())
 
-	registerEngineRoute(r, deps, "/compute", "result.json", "")
-	registerEngineRoute(r, deps, "/analytics", "analytics.json", "analytics")
-	registerEngineRoute(r, deps, "/heat", "heat_result.json", "heat")
+	r.Use(s.corsMiddleware(r))
+	r.Use(s.rateLimit())
 
-	// health endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	s.registerAPI(r.Group("/v1"))
+	// The unprefixed routes predate /v1 and stay as deprecated aliases so the
+	// current frontend keeps working.
+	s.registerAPI(r.Group("", deprecatedRoute()))
 
+	r.GET("/livez", handleLive)
+	r.GET("/readyz", s.handleHealth)
+	r.GET("/health", s.handleHealth)
+	keyed := s.requireAPIKey()
+	r.GET("/engine/version", keyed, s.handleEngineVersion)
+	r.GET("/selftest", keyed, s.handleSelfTest)
+	r.POST("/shutdown", keyed, s.handleShutdown)
+	r.GET(metricsPath, s.metrics.handler())
+	r.GET("/cache/stats", s.handleCacheStats)
+	r.DELETE("/cache", keyed, s.handleFlushCache)
+	if s.cfg.enablePprof {
+		s.registerPprof(r)
+	}
 
-	
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(handleNoRoute)
+	r.NoMethod(handleNoMethod(r))
 
 	return r
 }
 
+// registerAPI mounts the versioned API endpoints on g.
+func (s *server) registerAPI(g gin.IRouter) {
+	// JSON endpoints read at most MAX_BODY_BYTES; batches carry many pairs and
+	// get MAX_BATCH_BODY_BYTES. Uploads enforce MAX_UPLOAD_BYTES themselves.
+	// Every route that runs the engine, may fetch a remote model or changes
+	// the model directory needs the API key, whatever its method.
+	keyed := s.requireAPIKey()
+	api := g.Group("", keyed, limitBody(s.cfg.maxBodyBytes), requireJSON(), s.idempotency())
+	s.registerEngineRoute(api, "/compute", batchModes["dijkstra"])
+	s.registerEngineRoute(api, "/analytics", batchModes["analytics"])
+	s.registerEngineRoute(api, "/heat", batchModes["heat"])
+	s.registerEngineRoute(api, "/compute_astar_path", batchModes["astar"])
+	s.registerEngineRoute(api, "/compute_bfs_path", batchModes["bfs"])
+	s.registerEngineRoute(api, "/compute_bellman_ford_path", batchModes["bellman"])
+	s.registerEngineRoute(api, "/compute_ksp", batchModes["ksp"])
+	s.registerEngineRoute(api, "/compute_mst", batchModes["mst"])
+	s.registerEngineRoute(api, "/compute_diameter", batchModes["diameter"])
+	g.POST("/compute_batch", keyed, limitBody(s.cfg.batchBodyBytes), requireJSON(), s.idempotency(), s.handleBatch)
+	api.POST("/compute_multi", s.handleMulti)
+	api.POST("/compute_all", s.handleComputeAll)
+	api.POST("/compute_stream", s.handleStream)
+	api.POST("/validate", s.handleValidate)
+	api.POST("/warmup", s.handleWarmup)
+
+	g.GET("/models", s.handleListModels)
+	g.POST("/models", keyed, s.handleUploadModel)
+	g.DELETE("/models/:name", keyed, s.handleDeleteModel)
+	g.GET("/models/:name/info", s.handleModelInfo)
+
+	g.GET("/history", s.handleHistory)
+}
+
 func main() {
-	r := buildRouter(appDeps{})
+	tp, shutdownTracing, err := setupTracing(context.Background())
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+	s := newServer(appDeps{tracerProvider: tp})
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		log.Fatalf("failed to determine project root: %v", err)
+	}
+	enginePath := resolveEnginePath(s.cfg.enginePath, projectRoot)
+	if err := checkEngineExecutable(enginePath); err != nil {
+		log.Fatalf("engine binary unusable (set ENGINE_PATH to override): %v", err)
+	}
+	log.Printf("using engine %s (version %s)", enginePath, s.cachedEngineVersion())
+	if s.cfg.outputDir != "" {
+		if err := os.MkdirAll(s.cfg.outputDir, 0o755); err != nil {
+			log.Fatalf("failed to create ENGINE_OUTPUT_DIR: %v", err)
+		}
+	}
+	if n, err := sweepStaleOutputDirs(s.engineOutputBase(), time.Now().Add(-staleOutputAge)); err != nil {
+		log.Printf("skipping stale engine output sweep: %v", err)
+	} else if n > 0 {
+		log.Printf("removed %d stale engine output directories", n)
+	}
+
+	ln, err := net.Listen("tcp", s.cfg.listenAddr)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", s.cfg.listenAddr, err)
+	}
+	log.Printf("listening on %s", ln.Addr())
+
+	ctx, stop := signal.NotifyContext(s.stopping, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := s.reloadModeDefaults(); err != nil {
+				log.Printf("keeping previous mode defaults: %v", err)
+				continue
+			}
+			log.Printf("reloaded mode defaults from %s", s.cfg.modeDefaultsFile)
+		}
+	}()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{Handler: s.router()}
+	if err := serve(ctx, srv, ln, s.engines, s.cfg.shutdownGrace); err != nil {
+		log.Fatalf("server error: %v", err)
 	}
-	_ = r.Run(":" + port)
 }
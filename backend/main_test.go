@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -16,6 +19,17 @@ type engineCall struct {
 	projectRoot string
 	enginePath  string
 	args        []string
+	outputDir   string
+}
+
+// splitOutputDir separates the per-run output directory flag from the engine
+// arguments; dir is empty when the flag is missing.
+func splitOutputDir(args []string) (rest []string, dir string) {
+	n := len(args)
+	if n < 2 || args[n-2] != outputDirFlag {
+		return append([]string(nil), args...), ""
+	}
+	return append([]string(nil), args[:n-2]...), args[n-1]
 }
 
 func newTestRouter(deps appDeps) *gin.Engine {
@@ -31,6 +45,26 @@ func performRequest(router http.Handler, method, path, body string) *httptest.Re
 	return w
 }
 
+// newTestProject creates a project root whose data directory holds an OBJ
+// model with the given number of vertices.
+func newTestProject(t *testing.T, modelName string, vertexCount int) string {
+	t.Helper()
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "frontend", "public", "data")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+
+	var obj strings.Builder
+	for i := 0; i < vertexCount; i++ {
+		fmt.Fprintf(&obj, "v %d 0 0\n", i)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, modelName), []byte(obj.String()), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	return root
+}
+
 func decodeJSONBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
 	t.Helper()
 	out := map[string]any{}
@@ -40,30 +74,14 @@ func decodeJSONBody(t *testing.T, w *httptest.ResponseRecorder) map[string]any {
 	return out
 }
 
-func TestHealthEndpointReturnsOk(t *testing.T) {
-	router := newTestRouter(appDeps{
-		resolveProjectRoot: func() (string, error) {
-			return "/tmp/project", nil
-		},
-		runEngine: func(projectRoot, enginePath string, args ...string) ([]byte, error) {
-			t.Fatalf("runEngine should not be called for health endpoint")
-			return nil, nil
-		},
-		readFile: func(path string) ([]byte, error) {
-			t.Fatalf("readFile should not be called for health endpoint")
-			return nil, nil
-		},
-	})
-
-	w := performRequest(router, http.MethodGet, "/health", "")
-	if w.Code != http.StatusOK {
-		t.Fatalf("expected status 200, got %d", w.Code)
-	}
-
-	body := decodeJSONBody(t, w)
-	if got, ok := body["status"].(string); !ok || got != "ok" {
-		t.Fatalf("expected status=ok, got %#v", body["status"])
+// errorDetails returns the "details" object of a structured error response.
+func errorDetails(t *testing.T, body map[string]any) map[string]any {
+	t.Helper()
+	details, ok := body["details"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected details object in error response, got %#v", body["details"])
 	}
+	return details
 }
 
 func TestInvalidJSONReturnsBadRequestForAllPostRoutes(t *testing.T) {
@@ -74,14 +92,14 @@ func TestInvalidJSONReturnsBadRequestForAllPostRoutes(t *testing.T) {
 			runCalled := false
 			router := newTestRouter(appDeps{
 				resolveProjectRoot: func() (string, error) {
-					return "/tmp/project", nil
+					return newTestProject(t, "mesh.obj", 16), nil
 				},
-				runEngine: func(projectRoot, enginePath string, args ...string) ([]byte, error) {
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
 					runCalled = true
 					return nil, nil
 				},
 				readFile: func(path string) ([]byte, error) {
-					return []byte(`{"ok":true}`), nil
+					return []byte(`{"path":[],"curves":[]}`), nil
 				},
 			})
 
@@ -97,25 +115,25 @@ func TestInvalidJSONReturnsBadRequestForAllPostRoutes(t *testing.T) {
 }
 
 func TestComputeSanitizesModelPathTraversalInput(t *testing.T) {
-	const projectRoot = "/tmp/project"
+	projectRoot := newTestProject(t, "passwd.obj", 16)
 	var got engineCall
 
 	router := newTestRouter(appDeps{
 		resolveProjectRoot: func() (string, error) {
 			return projectRoot, nil
 		},
-		runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 			got.projectRoot = projectRootArg
 			got.enginePath = enginePath
-			got.args = append([]string(nil), args...)
+			got.args, got.outputDir = splitOutputDir(args)
 			return []byte(""), nil
 		},
 		readFile: func(path string) ([]byte, error) {
-			return []byte(`{"ok":true}`), nil
+			return []byte(`{"path":[],"curves":[]}`), nil
 		},
 	})
 
-	body := `{"start":1,"end":2,"model":"../../etc/passwd"}`
+	body := `{"start":1,"end":2,"model":"../../etc/passwd.obj"}`
 	w := performRequest(router, http.MethodPost, "/compute", body)
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d", w.Code)
@@ -134,14 +152,14 @@ func TestComputeSanitizesModelPathTraversalInput(t *testing.T) {
 		t.Fatalf("expected 3 args, got %d (%v)", len(got.args), got.args)
 	}
 
-	expectedModelPath := filepath.Join(projectRoot, "frontend", "public", "data", "passwd")
+	expectedModelPath := filepath.Join(projectRoot, "frontend", "public", "data", "passwd.obj")
 	if got.args[2] != expectedModelPath {
 		t.Fatalf("expected sanitized model path %q, got %q", expectedModelPath, got.args[2])
 	}
 }
 
 func TestRouteModeAndResultMappingForAnalyticsAndHeat(t *testing.T) {
-	const projectRoot = "/tmp/project"
+	projectRoot := newTestProject(t, "mesh.obj", 16)
 	cases := []struct {
 		name       string
 		route      string
@@ -160,15 +178,15 @@ func TestRouteModeAndResultMappingForAnalyticsAndHeat(t *testing.T) {
 				resolveProjectRoot: func() (string, error) {
 					return projectRoot, nil
 				},
-				runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 					got.projectRoot = projectRootArg
 					got.enginePath = enginePath
-					got.args = append([]string(nil), args...)
+					got.args, got.outputDir = splitOutputDir(args)
 					return []byte(""), nil
 				},
 				readFile: func(path string) ([]byte, error) {
 					readPath = path
-					return []byte(`{"ok":true}`), nil
+					return []byte(`{"path":[],"curves":[]}`), nil
 				},
 			})
 
@@ -189,7 +207,7 @@ func TestRouteModeAndResultMappingForAnalyticsAndHeat(t *testing.T) {
 				}
 			}
 
-			expectedReadPath := filepath.Join(projectRoot, "frontend", "public", tc.resultFile)
+			expectedReadPath := filepath.Join(got.outputDir, tc.resultFile)
 			if readPath != expectedReadPath {
 				t.Fatalf("expected readFile path %q, got %q", expectedReadPath, readPath)
 			}
@@ -198,12 +216,13 @@ func TestRouteModeAndResultMappingForAnalyticsAndHeat(t *testing.T) {
 }
 
 func TestComputeEngineFailureReturnsErrorAndModelPath(t *testing.T) {
-	const projectRoot = "/tmp/project"
+	t.Setenv("DEBUG", "true")
+	projectRoot := newTestProject(t, "evil.obj", 16)
 	router := newTestRouter(appDeps{
 		resolveProjectRoot: func() (string, error) {
 			return projectRoot, nil
 		},
-		runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 			return []byte("engine exploded\n"), errors.New("exit status 1")
 		},
 		readFile: func(path string) ([]byte, error) {
@@ -219,22 +238,47 @@ func TestComputeEngineFailureReturnsErrorAndModelPath(t *testing.T) {
 	}
 
 	body := decodeJSONBody(t, w)
-	if got, ok := body["error"].(string); !ok || got != "engine exploded" {
-		t.Fatalf("expected error 'engine exploded', got %#v", body["error"])
+	if got, ok := body["message"].(string); !ok || got != "engine exploded" {
+		t.Fatalf("expected error 'engine exploded', got %#v", body["message"])
 	}
 
 	expectedModelPath := filepath.Join(projectRoot, "frontend", "public", "data", "evil.obj")
-	if got, ok := body["modelPath"].(string); !ok || got != expectedModelPath {
-		t.Fatalf("expected modelPath %q, got %#v", expectedModelPath, body["modelPath"])
+	details := errorDetails(t, body)
+	if got, ok := details["modelPath"].(string); !ok || got != expectedModelPath {
+		t.Fatalf("expected modelPath %q, got %#v", expectedModelPath, details["modelPath"])
+	}
+}
+
+func TestComputeEngineFailureHidesModelPathOutsideDebug(t *testing.T) {
+	t.Setenv("DEBUG", "")
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return []byte("Error: Could not load " + modelPath + "\n"), errors.New("exit status 1")
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	body := decodeJSONBody(t, w)
+	if w.Code != http.StatusInternalServerError || body["code"] != string(codeEngineFailed) {
+		t.Fatalf("expected 500 ENGINE_FAILED, got %d %v", w.Code, body)
+	}
+	if got := body["message"]; got != "Error: Could not load mesh.obj" {
+		t.Fatalf("expected the model path to be redacted from the message, got %#v", got)
+	}
+	if details, _ := body["details"].(map[string]any); details["modelPath"] != nil {
+		t.Fatalf("expected no modelPath without DEBUG, got %v", body)
 	}
 }
 
 func TestComputeEngineFailureFallsBackToErrorTextWhenOutputEmpty(t *testing.T) {
 	router := newTestRouter(appDeps{
 		resolveProjectRoot: func() (string, error) {
-			return "/tmp/project", nil
+			return newTestProject(t, "mesh.obj", 16), nil
 		},
-		runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 			return []byte("\n\t"), errors.New("exit status 7")
 		},
 		readFile: func(path string) ([]byte, error) {
@@ -250,8 +294,8 @@ func TestComputeEngineFailureFallsBackToErrorTextWhenOutputEmpty(t *testing.T) {
 	}
 
 	body := decodeJSONBody(t, w)
-	if got, ok := body["error"].(string); !ok || got != "exit status 7" {
-		t.Fatalf("expected error 'exit status 7', got %#v", body["error"])
+	if got, ok := body["message"].(string); !ok || got != "exit status 7" {
+		t.Fatalf("expected error 'exit status 7', got %#v", body["message"])
 	}
 }
 
@@ -261,7 +305,7 @@ func TestResolveProjectRootFailureReturnsInternalServerError(t *testing.T) {
 		resolveProjectRoot: func() (string, error) {
 			return "", errors.New("cannot resolve")
 		},
-		runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 			runCalled = true
 			return nil, nil
 		},
@@ -280,8 +324,8 @@ func TestResolveProjectRootFailureReturnsInternalServerError(t *testing.T) {
 	}
 
 	body := decodeJSONBody(t, w)
-	if got, ok := body["error"].(string); !ok || got != "failed to determine project root" {
-		t.Fatalf("expected project-root error message, got %#v", body["error"])
+	if got, ok := body["message"].(string); !ok || got != "failed to determine project root" {
+		t.Fatalf("expected project-root error message, got %#v", body["message"])
 	}
 }
 
@@ -299,9 +343,9 @@ func TestReadFileFailureReturnsRouteSpecificMessage(t *testing.T) {
 		t.Run(tc.route, func(t *testing.T) {
 			router := newTestRouter(appDeps{
 				resolveProjectRoot: func() (string, error) {
-					return "/tmp/project", nil
+					return newTestProject(t, "mesh.obj", 16), nil
 				},
-				runEngine: func(projectRootArg, enginePath string, args ...string) ([]byte, error) {
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
 					return []byte(""), nil
 				},
 				readFile: func(path string) ([]byte, error) {
@@ -316,8 +360,265 @@ func TestReadFileFailureReturnsRouteSpecificMessage(t *testing.T) {
 			}
 
 			body := decodeJSONBody(t, w)
-			if got, ok := body["error"].(string); !ok || got != tc.expectedErr {
-				t.Fatalf("expected error %q, got %#v", tc.expectedErr, body["error"])
+			if got, ok := body["message"].(string); !ok || got != tc.expectedErr {
+				t.Fatalf("expected error %q, got %#v", tc.expectedErr, body["message"])
+			}
+		})
+	}
+}
+
+func TestEngineTimeoutReturnsGatewayTimeout(t *testing.T) {
+	t.Setenv("ENGINE_TIMEOUT", "20ms")
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(ctx context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		readFile: func(path string) ([]byte, error) {
+			t.Fatalf("readFile should not be called when the engine times out")
+			return nil, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute",
+		`{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d", w.Code)
+	}
+
+	body := decodeJSONBody(t, w)
+	if got, ok := body["message"].(string); !ok || got != "engine timed out after 20ms" {
+		t.Fatalf("expected timeout error message, got %#v", body["message"])
+	}
+}
+
+func TestEngineTimeoutCoversResultRead(t *testing.T) {
+	t.Setenv("ENGINE_TIMEOUT", "20ms")
+
+	release := make(chan struct{})
+	defer close(release)
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			<-release
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/heat",
+		`{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d", w.Code)
+	}
+}
+
+func TestComputeValidatesNodeRange(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	cases := []struct {
+		name      string
+		body      string
+		wantCode  int
+		wantField string
+		wantBody  string
+	}{
+		{name: "start equals end", body: `{"start":3,"end":3,"model":"mesh.obj"}`, wantCode: http.StatusOK,
+			wantBody: `{"path":[3],"totalDistance":0,"reachable":true}`},
+		{name: "last vertex", body: `{"start":0,"end":9,"model":"mesh.obj"}`, wantCode: http.StatusOK},
+		{name: "negative start", body: `{"start":-1,"end":3,"model":"mesh.obj"}`,
+			wantCode: http.StatusUnprocessableEntity, wantField: "start"},
+		{name: "negative end", body: `{"start":0,"end":-4,"model":"mesh.obj"}`,
+			wantCode: http.StatusUnprocessableEntity, wantField: "end"},
+		{name: "start beyond node count", body: `{"start":10,"end":3,"model":"mesh.obj"}`,
+			wantCode: http.StatusUnprocessableEntity, wantField: "start"},
+		{name: "end beyond node count", body: `{"start":0,"end":250,"model":"mesh.obj"}`,
+			wantCode: http.StatusUnprocessableEntity, wantField: "end"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runCalled := false
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return projectRoot, nil
+				},
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					runCalled = true
+					return []byte(""), nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					return []byte(`{"path":[],"curves":[]}`), nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", tc.body)
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected status %d, got %d (%s)", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantBody != "" {
+				if runCalled || w.Body.String() != tc.wantBody {
+					t.Fatalf("expected %s without running the engine, got %s (ran: %v)", tc.wantBody, w.Body.String(), runCalled)
+				}
+				return
+			}
+			if tc.wantField == "" {
+				if !runCalled {
+					t.Fatalf("expected engine to run for a valid request")
+				}
+				return
+			}
+
+			if runCalled {
+				t.Fatalf("runEngine should not be called for an out-of-range %s", tc.wantField)
+			}
+			body := decodeJSONBody(t, w)
+			if got := body["code"]; got != string(codeInvalidInput) {
+				t.Fatalf("expected code %s, got %#v", codeInvalidInput, got)
+			}
+			if got := errorDetails(t, body)["field"]; got != tc.wantField {
+				t.Fatalf("expected field %q, got %#v", tc.wantField, got)
+			}
+			if msg, _ := body["message"].(string); !strings.Contains(msg, tc.wantField) {
+				t.Fatalf("expected error to name %q, got %q", tc.wantField, msg)
+			}
+		})
+	}
+}
+
+func TestComputeRejectsHugeAndMalformedIndices(t *testing.T) {
+	t.Setenv("MAX_VERTEX_INDEX", "5")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	runs := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			runs++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[]}`), nil },
+	})
+
+	cases := []struct {
+		name, method, path, body string
+		wantCode                 int
+	}{
+		{name: "within limit", method: http.MethodPost, path: "/compute", body: `{"start":0,"end":5,"model":"mesh.obj"}`, wantCode: http.StatusOK},
+		{name: "above limit", method: http.MethodPost, path: "/compute", body: `{"start":0,"end":6,"model":"mesh.obj"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "huge start", method: http.MethodPost, path: "/compute", body: `{"start":9007199254740993,"end":1,"model":"mesh.obj"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "huge negative", method: http.MethodPost, path: "/compute", body: `{"start":-9223372036854775808,"end":1,"model":"mesh.obj"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "overflows int", method: http.MethodPost, path: "/compute", body: `{"start":0,"end":1e30,"model":"mesh.obj"}`, wantCode: http.StatusBadRequest},
+		{name: "fraction", method: http.MethodPost, path: "/compute", body: `{"start":1.5,"end":1,"model":"mesh.obj"}`, wantCode: http.StatusBadRequest},
+		{name: "query overflow", method: http.MethodGet, path: "/compute?start=99999999999999999999&end=1&model=mesh.obj", wantCode: http.StatusBadRequest},
+		{name: "query above limit", method: http.MethodGet, path: "/compute?start=4294967296&end=1&model=mesh.obj", wantCode: http.StatusUnprocessableEntity},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runs = 0
+			w := performRequest(router, tc.method, tc.path, tc.body)
+			if w.Code != tc.wantCode {
+				t.Fatalf("expected status %d, got %d (%s)", tc.wantCode, w.Code, w.Body.String())
+			}
+			if tc.wantCode != http.StatusOK && runs != 0 {
+				t.Fatalf("expected the engine not to run for a rejected index")
+			}
+		})
+	}
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":6,"model":"mesh.obj"}`)
+	if details := errorDetails(t, decodeJSONBody(t, w)); details["field"] != "end" || details["max"] != 5.0 {
+		t.Fatalf("expected the end field and its max in details, got %v", details)
+	}
+}
+
+func TestComputeRangeErrorReportsValidRange(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/analytics",
+		`{"start":0,"end":12,"model":"mesh.obj"}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", w.Code)
+	}
+
+	body := decodeJSONBody(t, w)
+	if got, _ := body["message"].(string); got != "end must be between 0 and 9, got 12" {
+		t.Fatalf("unexpected error message %q", got)
+	}
+	validRange, ok := errorDetails(t, body)["validRange"].([]any)
+	if !ok || len(validRange) != 2 || validRange[0] != float64(0) || validRange[1] != float64(9) {
+		t.Fatalf("expected validRange [0 9], got %#v", validRange)
+	}
+}
+
+func TestComputeRequiresStartAndEnd(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name     string
+		body     string
+		status   int
+		field    string
+		expected []string
+	}{
+		{name: "missing start", body: `{"end":3,"model":"mesh.obj"}`, status: http.StatusBadRequest, field: "start"},
+		{name: "missing end", body: `{"start":3,"model":"mesh.obj"}`, status: http.StatusBadRequest, field: "end"},
+		{name: "missing both", body: `{"model":"mesh.obj"}`, status: http.StatusBadRequest, field: "start"},
+		{name: "explicit zero", body: `{"start":0,"end":4,"model":"mesh.obj"}`, status: http.StatusOK, expected: []string{"0", "4"}},
+		{name: "both present", body: `{"start":2,"end":7,"model":"mesh.obj"}`, status: http.StatusOK, expected: []string{"2", "7"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = engineCall{}
+			w := performRequest(router, http.MethodPost, "/compute", tc.body)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d (%s)", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status != http.StatusOK {
+				body := decodeJSONBody(t, w)
+				if msg := fmt.Sprintf("missing required field %q", tc.field); body["message"] != msg {
+					t.Fatalf("expected message %q, got %v", msg, body["message"])
+				}
+				if errorDetails(t, body)["field"] != tc.field {
+					t.Fatalf("expected field %q in details, got %v", tc.field, body)
+				}
+				if got.args != nil {
+					t.Fatalf("expected the engine not to run")
+				}
+				return
+			}
+			if got.args[0] != tc.expected[0] || got.args[1] != tc.expected[1] {
+				t.Fatalf("expected endpoints %v, got %v", tc.expected, got.args)
 			}
 		})
 	}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// resultMeta is the ?meta=true provenance block. For a cached result,
+// computedAt and durationMs describe the engine run that produced it.
+type resultMeta struct {
+	ComputedAt    time.Time `json:"computedAt"`
+	EngineVersion string    `json:"engineVersion"`
+	Model         string    `json:"model"`
+	Mode          string    `json:"mode"`
+	DurationMs    int64     `json:"durationMs"`
+}
+
+// resultMetaFor describes how res was produced for job. Results no engine
+// ran for, such as a same-node path, are stamped with now.
+func (s *server) resultMetaFor(job engineJob, res engineResult, now time.Time) resultMeta {
+	computedAt := res.computedAt
+	if computedAt.IsZero() {
+		computedAt = now
+	}
+	return resultMeta{
+		ComputedAt:    computedAt.UTC(),
+		EngineVersion: s.cachedEngineVersion(),
+		Model:         job.model,
+		Mode:          job.mode,
+		DurationMs:    durationMillis(res.engineTime),
+	}
+}
+
+// wrapMeta adds a "_meta" key to body. An enveloped body already is an
+// object and gains the key in place; a bare one becomes
+// {"_meta":...,"result":body}.
+func wrapMeta(meta resultMeta, body []byte, enveloped bool) []byte {
+	encoded, _ := json.Marshal(meta)
+	body = bytes.TrimSpace(body)
+	var out bytes.Buffer
+	out.Grow(len(`{"_meta":,"result":}`) + len(encoded) + len(body))
+	out.WriteString(`{"_meta":`)
+	out.Write(encoded)
+	if enveloped {
+		out.WriteByte(',')
+		out.Write(body[1:])
+		return out.Bytes()
+	}
+	out.WriteString(`,"result":`)
+	out.Write(body)
+	out.WriteByte('}')
+	return out.Bytes()
+}
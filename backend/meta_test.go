@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newMetaRouter(t *testing.T) http.Handler {
+	t.Helper()
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, args ...string) ([]byte, error) {
+			if len(args) == 1 && args[0] == "--version" {
+				return []byte("geodesic-engine 1.4.0\n"), nil
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[1,3,4]}`), nil },
+	})
+}
+
+func TestMetaDescribesTheResult(t *testing.T) {
+	router := newMetaRouter(t)
+	before := time.Now().UTC().Truncate(time.Second)
+
+	w := performRequest(router, http.MethodPost, "/compute?meta=true", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	meta, ok := body["_meta"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a _meta block, got %s", w.Body.String())
+	}
+	if meta["model"] != "mesh.obj" || meta["mode"] != "dijkstra" || meta["engineVersion"] != "geodesic-engine 1.4.0" {
+		t.Fatalf("unexpected meta %v", meta)
+	}
+	if ms, _ := meta["durationMs"].(float64); ms < 1 {
+		t.Fatalf("expected the engine duration, got %v", meta["durationMs"])
+	}
+	computedAt, err := time.Parse(time.RFC3339Nano, meta["computedAt"].(string))
+	if err != nil || computedAt.Before(before) {
+		t.Fatalf("expected computedAt after %v, got %v (%v)", before, meta["computedAt"], err)
+	}
+	if result, ok := body["result"].(map[string]any); !ok || result["path"] == nil {
+		t.Fatalf("expected the payload under result, got %s", w.Body.String())
+	}
+
+	w = performRequest(router, http.MethodPost, "/compute?meta=1", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	if w.Header().Get(cacheStatusHeader) != string(cacheHit) {
+		t.Fatalf("expected the second request to hit the cache")
+	}
+	if cached := decodeJSONBody(t, w)["_meta"].(map[string]any); cached["computedAt"] != meta["computedAt"] || cached["durationMs"] != meta["durationMs"] {
+		t.Fatalf("expected a cached result to keep its provenance, got %v want %v", cached, meta)
+	}
+}
+
+func TestMetaIsOmittedByDefault(t *testing.T) {
+	router := newMetaRouter(t)
+
+	for _, path := range []string{"/compute", "/compute?meta=false"} {
+		w := performRequest(router, http.MethodPost, path, `{"start":1,"end":4,"model":"mesh.obj"}`)
+		if w.Code != http.StatusOK || w.Body.String() != `{"path":[1,3,4]}` {
+			t.Fatalf("%s: expected the bare payload, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	w := performRequest(router, http.MethodPost, "/compute?meta=true&envelope=true", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	body := decodeJSONBody(t, w)
+	if body["_meta"] == nil || body["input"] == nil || body["result"] == nil {
+		t.Fatalf("expected _meta alongside the envelope, got %s", w.Body.String())
+	}
+}
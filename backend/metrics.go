@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsPath = "/metrics"
+
+// metrics holds the Prometheus collectors exported at /metrics. Each server
+// gets its own registry so tests don't share counters.
+type metrics struct {
+	registry       *prometheus.Registry
+	requests       *prometheus.CounterVec
+	engineDuration *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "geodesic_http_requests_total",
+			Help: "HTTP requests handled, by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		engineDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "geodesic_engine_duration_seconds",
+			Help:    "Wall-clock time of engine subprocess runs, by mode.",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"mode"}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.engineDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// middleware counts every request except scrapes of /metrics itself. Routes
+// are labeled by their pattern so path parameters don't explode cardinality.
+func (m *metrics) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == metricsPath {
+			return
+		}
+		if route == "" {
+			route = "unmatched"
+		}
+		m.requests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// watchCache exports the result cache's counters and size.
+func (m *metrics) watchCache(rc *resultCache) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "geodesic_cache_hits_total",
+			Help: "Result cache lookups that found a result.",
+		}, func() float64 { return float64(rc.hits.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "geodesic_cache_misses_total",
+			Help: "Result cache lookups that found nothing.",
+		}, func() float64 { return float64(rc.misses.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "geodesic_cache_evictions_total",
+			Help: "Results dropped to keep the cache within CACHE_SIZE.",
+		}, func() float64 { return float64(rc.evictions.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "geodesic_cache_entries",
+			Help: "Results currently held in the cache.",
+		}, func() float64 { return float64(rc.len()) }),
+	)
+}
+
+func (m *metrics) observeEngine(mode string, elapsed time.Duration) {
+	m.engineDuration.WithLabelValues(mode).Observe(elapsed.Seconds())
+}
+
+func (m *metrics) handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMetricsCountRequestsAndEngineRuns(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[0,1],"curves":[]}`), nil
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := performRequest(router, http.MethodPost, "/heat", `{"start":0,"end":1,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+	}
+	performRequest(router, http.MethodPost, "/heat", `{"start":0,"end":99,"model":"mesh.obj"}`)
+
+	performRequest(router, http.MethodGet, "/metrics", "")
+	w := performRequest(router, http.MethodGet, "/metrics", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 from /metrics, got %d", w.Code)
+	}
+	scrape := w.Body.String()
+	for _, want := range []string{
+		`geodesic_http_requests_total{method="POST",route="/heat",status="200"} 2`,
+		`geodesic_http_requests_total{method="POST",route="/heat",status="422"} 1`,
+		// The second request is a cache hit, so only one engine run is timed.
+		`geodesic_engine_duration_seconds_count{mode="heat"} 1`,
+	} {
+		if !strings.Contains(scrape, want) {
+			t.Fatalf("expected scrape to contain %q, got:\n%s", want, scrape)
+		}
+	}
+	if strings.Contains(scrape, `route="/metrics"`) {
+		t.Fatalf("expected /metrics scrapes to be excluded from request counts")
+	}
+}
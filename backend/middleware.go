@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	// Gin context keys shared between middleware and handlers.
+	ctxRequestID      = "requestID"
+	ctxComputeRequest = "computeRequest"
+)
+
+// newRequestID returns a random RFC 4122 version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// validRequestID accepts caller-supplied IDs that are safe to echo and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r == '-' || r == '_' || r == '.' ||
+			('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func requestIDFrom(c *gin.Context) string {
+	return c.GetString(ctxRequestID)
+}
+
+// requestLogger tags each request with a correlation ID (reusing a valid
+// incoming X-Request-ID) and logs one structured line when it completes.
+func (s *server) requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(ctxRequestID, id)
+		c.Header(requestIDHeader, id)
+
+		started := time.Now()
+		c.Next()
+		if c.FullPath() == metricsPath {
+			return
+		}
+
+		attrs := []any{
+			"request_id", id,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(started).Milliseconds(),
+		}
+		if v, ok := c.Get(ctxComputeRequest); ok {
+			req := v.(computeRequest)
+			attrs = append(attrs, "model", req.Model)
+			if req.Start != nil {
+				attrs = append(attrs, "start", *req.Start)
+			}
+			if req.End != nil {
+				attrs = append(attrs, "end", *req.End)
+			}
+		}
+		s.deps.logger.Info("request", attrs...)
+	}
+}
+
+// recoverPanics turns a handler panic into the standard INTERNAL error body.
+// The panic value and stack trace are only logged, never sent to the client.
+func (s *server) recoverPanics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			s.deps.logger.Error("panic",
+				"request_id", requestIDFrom(c),
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"panic", fmt.Sprint(rec),
+				"stack", string(debug.Stack()))
+			respondError(c, newAPIError(http.StatusInternalServerError, codeInternal, "internal server error"))
+		}()
+		c.Next()
+	}
+}
+
+// deprecatedRoute marks responses from the legacy unprefixed routes and points
+// clients at their /v1 successor.
+func deprecatedRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("</v1%s>; rel=\"successor-version\"", c.FullPath()))
+		c.Next()
+	}
+}
+
+// requestTimeout bounds the whole request with REQUEST_TIMEOUT. Engine runs
+// derive their ENGINE_TIMEOUT from the request context, so whichever deadline
+// is tighter wins. A handler that gives up without responding gets a 503.
+func (s *server) requestTimeout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), s.cfg.requestTimeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			respondError(c, errCanceled(ctx.Err()))
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDHeaderIsSet(t *testing.T) {
+	router := newPayloadRouter(t, []byte(`{"path":[],"curves":[]}`))
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	id := w.Header().Get(requestIDHeader)
+	if !uuidPattern.MatchString(id) {
+		t.Fatalf("expected a UUID request ID, got %q", id)
+	}
+
+	other := performRequest(router, http.MethodGet, "/models", "").Header().Get(requestIDHeader)
+	if other == "" || other == id {
+		t.Fatalf("expected a fresh request ID per request, got %q then %q", id, other)
+	}
+}
+
+func TestIncomingRequestIDIsReused(t *testing.T) {
+	router := newPayloadRouter(t, []byte(`{"path":[],"curves":[]}`))
+
+	req := httptest.NewRequest(http.MethodGet, "/models", nil)
+	req.Header.Set(requestIDHeader, "client-abc.123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(requestIDHeader); got != "client-abc.123" {
+		t.Fatalf("expected incoming request ID to be echoed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/models", nil)
+	req.Header.Set(requestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if got := w.Header().Get(requestIDHeader); !uuidPattern.MatchString(got) {
+		t.Fatalf("expected unsafe request ID to be replaced, got %q", got)
+	}
+}
+
+func TestEngineFailureIsLoggedWithRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return []byte("Error: Could not find mesh.obj"), errors.New("exit status 1")
+		},
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	id := w.Header().Get(requestIDHeader)
+
+	var engineLine, requestLine string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		switch {
+		case strings.Contains(line, `msg="engine failed"`):
+			engineLine = line
+		case strings.Contains(line, "msg=request"):
+			requestLine = line
+		}
+	}
+	if !strings.Contains(engineLine, "request_id="+id) || !strings.Contains(engineLine, "Could not find mesh.obj") {
+		t.Fatalf("expected engine output logged with request ID %s, got %q", id, engineLine)
+	}
+	for _, want := range []string{"request_id=" + id, "method=POST", "path=/compute", "status=500", "model=mesh.obj", "start=0", "end=1", "duration_ms="} {
+		if !strings.Contains(requestLine, want) {
+			t.Fatalf("expected request log to contain %q, got %q", want, requestLine)
+		}
+	}
+}
+
+func TestVersionedAndLegacyRoutesAgree(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[],"file":"` + filepath.Base(path) + `"}`), nil
+		},
+	})
+
+	for _, route := range []string{"/compute", "/analytics", "/heat"} {
+		t.Run(route, func(t *testing.T) {
+			body := `{"start":1,"end":2,"model":"mesh.obj"}`
+			legacy := performRequest(router, http.MethodPost, route, body)
+			versioned := performRequest(router, http.MethodPost, "/v1"+route, body)
+
+			if legacy.Code != http.StatusOK || versioned.Code != http.StatusOK {
+				t.Fatalf("expected 200 from both, got legacy=%d v1=%d", legacy.Code, versioned.Code)
+			}
+			if legacy.Body.String() != versioned.Body.String() {
+				t.Fatalf("expected equivalent results, got legacy=%s v1=%s", legacy.Body.String(), versioned.Body.String())
+			}
+			if legacy.Header().Get("Deprecation") != "true" {
+				t.Fatalf("expected legacy route to carry Deprecation: true")
+			}
+			if link := legacy.Header().Get("Link"); link != `</v1`+route+`>; rel="successor-version"` {
+				t.Fatalf("unexpected Link header %q", link)
+			}
+			if versioned.Header().Get("Deprecation") != "" {
+				t.Fatalf("expected /v1 route not to be deprecated")
+			}
+		})
+	}
+
+	if w := performRequest(router, http.MethodGet, "/v1/models", ""); w.Code != http.StatusOK {
+		t.Fatalf("expected /v1/models to be served, got %d", w.Code)
+	}
+}
+
+func TestPanicsReturnJSONInternalError(t *testing.T) {
+	var logs bytes.Buffer
+	router := newTestRouter(appDeps{
+		logger: slog.New(slog.NewTextHandler(&logs, nil)),
+	})
+	router.GET("/explode", func(c *gin.Context) {
+		var result map[string]any
+		_ = result["path"].([]any)[0]
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/explode", nil)
+	req.Header.Set(requestIDHeader, "panic-trace-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("expected a JSON response, got %q", ct)
+	}
+	if body := w.Body.String(); body != `{"code":"INTERNAL","message":"internal server error"}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+
+	logged := logs.String()
+	if !strings.Contains(logged, "request_id=panic-trace-1") || !strings.Contains(logged, "interface conversion") {
+		t.Fatalf("expected the panic to be logged with its request ID, got %q", logged)
+	}
+	if !strings.Contains(logged, "stack=") {
+		t.Fatalf("expected a stack trace in the logs")
+	}
+	if strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("stack trace leaked to the client")
+	}
+}
+
+func TestRequestTimeoutStopsSlowHandlers(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "20ms")
+	router := newTestRouter(appDeps{})
+	router.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(2 * time.Second):
+			c.Status(http.StatusOK)
+		}
+	})
+
+	w := performRequest(router, http.MethodGet, "/slow", "")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", w.Code)
+	}
+	if body := decodeJSONBody(t, w); body["code"] != string(codeRequestTimeout) {
+		t.Fatalf("expected code %s, got %v", codeRequestTimeout, body["code"])
+	}
+}
+
+func TestTighterOfRequestAndEngineTimeoutWins(t *testing.T) {
+	cases := []struct {
+		name           string
+		requestTimeout string
+		engineTimeout  string
+		status         int
+		code           errorCode
+	}{
+		{name: "request deadline first", requestTimeout: "20ms", engineTimeout: "5s",
+			status: http.StatusServiceUnavailable, code: codeRequestTimeout},
+		{name: "engine deadline first", requestTimeout: "5s", engineTimeout: "20ms",
+			status: http.StatusGatewayTimeout, code: codeEngineTimeout},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("REQUEST_TIMEOUT", tc.requestTimeout)
+			t.Setenv("ENGINE_TIMEOUT", tc.engineTimeout)
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(ctx context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if body := decodeJSONBody(t, w); body["code"] != string(tc.code) {
+				t.Fatalf("expected code %s, got %v", tc.code, body["code"])
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// defaultModeDefaultsFile is read from the working directory unless
+// MODE_DEFAULTS_FILE names another file.
+const defaultModeDefaultsFile = "defaults.json"
+
+// modeParams are the request parameters defaults.json may preset for a mode,
+// for example {"heat": {"radius": 2}, "ksp": {"k": 5}}.
+type modeParams struct {
+	Heuristic string   `json:"heuristic,omitempty"`
+	K         *int     `json:"k,omitempty"`
+	Radius    *float64 `json:"radius,omitempty"`
+	Weight    *float64 `json:"weight,omitempty"`
+}
+
+// applyTo fills the parameters req leaves unset; values the request sets win.
+func (p modeParams) applyTo(req computeRequest) computeRequest {
+	if req.Heuristic == "" {
+		req.Heuristic = p.Heuristic
+	}
+	if req.K == nil {
+		req.K = p.K
+	}
+	if req.Radius == nil {
+		req.Radius = p.Radius
+	}
+	if req.Weight == nil {
+		req.Weight = p.Weight
+	}
+	return req
+}
+
+// loadModeDefaults reads a JSON object mapping mode names to modeParams. A
+// missing file means no defaults. Unknown modes or fields, and defaults the
+// mode would reject, fail the whole file so a typo does not go unnoticed.
+func loadModeDefaults(path string) (map[string]modeParams, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]modeParams{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defaults := map[string]modeParams{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&defaults); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for mode, params := range defaults {
+		route, ok := batchModes[mode]
+		if !ok || mode == "" {
+			return nil, fmt.Errorf("%s: unknown mode %q", path, mode)
+		}
+		if route.extraArgs == nil {
+			continue
+		}
+		if _, err := route.extraArgs(params.applyTo(computeRequest{})); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, mode, err)
+		}
+	}
+	return defaults, nil
+}
+
+// reloadModeDefaults rereads MODE_DEFAULTS_FILE. On error the defaults in use
+// are kept.
+func (s *server) reloadModeDefaults() error {
+	defaults, err := loadModeDefaults(s.cfg.modeDefaultsFile)
+	if err != nil {
+		return err
+	}
+	s.modeDefaults.Store(&defaults)
+	return nil
+}
+
+// withModeDefaults merges the configured defaults for mode under req.
+func (s *server) withModeDefaults(mode string, req computeRequest) computeRequest {
+	defaults := s.modeDefaults.Load()
+	if defaults == nil {
+		return req
+	}
+	return (*defaults)[mode].applyTo(req)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeModeDefaults(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "defaults.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestModeDefaultsFillOmittedParameters(t *testing.T) {
+	t.Setenv("MODE_DEFAULTS_FILE", writeModeDefaults(t, `{"heat":{"radius":2,"weight":3},"ksp":{"k":5}}`))
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			if strings.HasSuffix(path, "ksp_result.json") {
+				return []byte(`[]`), nil
+			}
+			return []byte(`{"curves":[]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name  string
+		route string
+		body  string
+		extra []string
+	}{
+		{name: "heat defaults", route: "/heat", body: `{"start":2,"end":7,"model":"mesh.obj"}`, extra: []string{"heat", "2", "3"}},
+		{name: "heat override", route: "/heat", body: `{"start":2,"end":7,"model":"mesh.obj","weight":0.5}`, extra: []string{"heat", "2", "0.5"}},
+		{name: "ksp default", route: "/compute_ksp", body: `{"start":2,"end":7,"model":"mesh.obj"}`, extra: []string{"ksp", "5"}},
+		{name: "ksp override", route: "/compute_ksp", body: `{"start":2,"end":7,"model":"mesh.obj","k":2}`, extra: []string{"ksp", "2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodPost, tc.route, tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+			}
+			if extra := strings.Join(got.args[3:], "|"); extra != strings.Join(tc.extra, "|") {
+				t.Fatalf("expected mode arguments %v, got %v", tc.extra, got.args[3:])
+			}
+		})
+	}
+}
+
+func TestModeDefaultsFileIsValidated(t *testing.T) {
+	cases := map[string]string{
+		"unknown mode":  `{"heta":{"radius":2}}`,
+		"unknown field": `{"heat":{"radious":2}}`,
+		"invalid value": `{"ksp":{"k":50}}`,
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadModeDefaults(writeModeDefaults(t, content)); err == nil {
+				t.Fatalf("expected %s to be rejected", content)
+			}
+		})
+	}
+	defaults, err := loadModeDefaults(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || len(defaults) != 0 {
+		t.Fatalf("expected a missing file to mean no defaults, got %v (%v)", defaults, err)
+	}
+}
+
+func TestReloadKeepsModeDefaultsOnError(t *testing.T) {
+	path := writeModeDefaults(t, `{"ksp":{"k":4}}`)
+	t.Setenv("MODE_DEFAULTS_FILE", path)
+	s := newServer(appDeps{})
+
+	if err := os.WriteFile(path, []byte(`{"ksp":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.reloadModeDefaults(); err == nil {
+		t.Fatalf("expected the truncated file to fail to load")
+	}
+	if k := s.withModeDefaults("ksp", computeRequest{}).K; k == nil || *k != 4 {
+		t.Fatalf("expected the previous default k=4 to be kept, got %v", k)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"ksp":{"k":6}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.reloadModeDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if k := s.withModeDefaults("ksp", computeRequest{}).K; k == nil || *k != 6 {
+		t.Fatalf("expected the reloaded default k=6, got %v", k)
+	}
+}
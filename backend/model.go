@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// supportedModelExtensions lists the accepted model formats. The engine loads
+// OBJ; CSV edge lists are converted by engineModelPath. Either may be gzipped.
+var supportedModelExtensions = map[string]bool{
+	".obj":      true,
+	csvModelExt: true,
+}
+
+// supportedExtensionList returns the allowlist in a stable order for messages.
+func supportedExtensionList() []string {
+	exts := make([]string, 0, len(supportedModelExtensions))
+	for ext := range supportedModelExtensions {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// checkModelExtension rejects names whose extension, compared
+// case-insensitively, is not in supportedModelExtensions. A trailing .gz is
+// looked through, so mesh.obj.gz is accepted and mesh.gz is not.
+func checkModelExtension(name string) *apiError {
+	if supportedModelExtensions[strings.ToLower(filepath.Ext(trimGzipExt(name)))] {
+		return nil
+	}
+	return newAPIError(415, codeUnsupportedModel,
+		fmt.Sprintf("unsupported model type, accepted: %s", strings.Join(supportedExtensionList(), ", ")))
+}
+
+// modelInfo is one entry of the GET /models listing.
+type modelInfo struct {
+	Name       string    `json:"name"`
+	SizeBytes  int64     `json:"sizeBytes"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+}
+
+// validationError reports a request field that is well-formed JSON but has
+// a value the engine cannot accept.
+type validationError struct {
+	field   string
+	message string
+	details map[string]any
+}
+
+func (e *validationError) Error() string { return e.message }
+
+// modelDir returns the directory models are read from: DATA_DIR when set
+// (relative values are taken from the project root), else frontend/public/data.
+func (s *server) modelDir(projectRoot string) string {
+	if s.cfg.dataDir == "" {
+		return filepath.Join(projectRoot, "frontend", "public", "data")
+	}
+	if filepath.IsAbs(s.cfg.dataDir) {
+		return filepath.Clean(s.cfg.dataDir)
+	}
+	return filepath.Join(projectRoot, s.cfg.dataDir)
+}
+
+// resolveModel maps a client-supplied model name to a file inside modelDir.
+// Only the final path element is kept, which prevents traversal such as
+// ../../etc/passwd.
+func (s *server) resolveModel(projectRoot, model string) (name, path string) {
+	name = filepath.Base(strings.ReplaceAll(model, "\\", "/"))
+	return name, filepath.Join(s.modelDir(projectRoot), name)
+}
+
+// locateModel returns the name and local path of a supported model, which is
+// either a file in the data directory or a downloaded copy of an http(s) URL.
+func (s *server) locateModel(ctx context.Context, projectRoot, model string) (name, path string, err error) {
+	if isRemoteModel(model) {
+		return s.remote.fetch(ctx, model)
+	}
+	name, path = s.resolveModel(projectRoot, model)
+	if apiErr := checkModelExtension(name); apiErr != nil {
+		return "", "", apiErr.withDetail("model", name)
+	}
+	return name, path, nil
+}
+
+// statModel returns the model file's info, or a MODEL_NOT_FOUND error when
+// nothing usable exists at path. Directories never count as models.
+func statModel(name, path string) (fs.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		notFound := newAPIError(404, codeModelNotFound, "model not found").withDetail("model", name)
+		if err != nil {
+			notFound.wrap(err)
+		}
+		return nil, notFound
+	}
+	return info, nil
+}
+
+// checkModelSize rejects a model larger than MAX_MODEL_BYTES, so huge files
+// fail fast instead of getting the engine OOM-killed.
+func (s *server) checkModelSize(name, path string) error {
+	info, err := statModel(name, path)
+	if err != nil {
+		return err
+	}
+	if s.cfg.maxModelBytes > 0 && info.Size() > s.cfg.maxModelBytes {
+		return newAPIError(http.StatusRequestEntityTooLarge, codePayloadTooLarge,
+			fmt.Sprintf("model is %d bytes, over the %d byte limit", info.Size(), s.cfg.maxModelBytes)).
+			withDetail("model", name).
+			withDetail("sizeBytes", info.Size()).
+			withDetail("limitBytes", s.cfg.maxModelBytes)
+	}
+	return nil
+}
+
+// emptyModelScanBytes bounds how much of a model checkModelNotEmpty reads.
+// A file whose first emptyModelScanBytes are all whitespace but which goes on
+// past them is left for the engine to judge.
+const emptyModelScanBytes = 64 << 10
+
+// checkModelNotEmpty rejects a zero-length or whitespace-only model, which
+// the engine would otherwise crash on.
+func checkModelNotEmpty(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return newAPIError(404, codeModelNotFound, "model not found").withDetail("model", name).wrap(err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, emptyModelScanBytes)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return toAPIError(err)
+	}
+	if n == len(buf) || len(bytes.TrimSpace(buf[:n])) > 0 {
+		return nil
+	}
+	return newAPIError(http.StatusUnprocessableEntity, codeEmptyModel, "model file is empty").
+		withDetail("model", name)
+}
+
+// defaultMaxVertexIndex is the largest start or end forwarded to the engine by
+// default; the engine keeps vertex indices in 32-bit ints.
+const defaultMaxVertexIndex = math.MaxInt32
+
+// checkIndexLimit rejects an explicit index above limit before the model is
+// read, so a hostile value never reaches the engine even when the model's
+// vertex count cannot be determined.
+func checkIndexLimit(field string, value *int, limit int) *validationError {
+	if value == nil || *value <= limit {
+		return nil
+	}
+	return &validationError{
+		field:   field,
+		message: fmt.Sprintf("%s must be at most %d, got %d", field, limit, *value),
+		details: map[string]any{"field": field, "value": *value, "max": limit},
+	}
+}
+
+// validateNodeIndex checks that value is a usable vertex index for a model
+// with nodeCount vertices. A negative nodeCount means the count is unknown,
+// in which case only the lower bound is enforced.
+func validateNodeIndex(field string, value, nodeCount int) *validationError {
+	if value < 0 {
+		return &validationError{
+			field:   field,
+			message: fmt.Sprintf("%s must be non-negative, got %d", field, value),
+			details: map[string]any{"field": field, "value": value},
+		}
+	}
+	if nodeCount < 0 || value < nodeCount {
+		return nil
+	}
+	if nodeCount == 0 {
+		return &validationError{
+			field:   field,
+			message: "model has no vertices",
+			details: map[string]any{"field": field, "value": value, "nodeCount": 0},
+		}
+	}
+	return &validationError{
+		field: field,
+		message: fmt.Sprintf("%s must be between 0 and %d, got %d",
+			field, nodeCount-1, value),
+		details: map[string]any{
+			"field":      field,
+			"value":      value,
+			"validRange": []int{0, nodeCount - 1},
+		},
+	}
+}
+
+// validateEndpoints checks start and end against the model's vertex count.
+// If the model cannot be read the upper bound is skipped and the engine is
+// left to report the problem. The vertex count comes from s.graphs, so a
+// model is only rescanned after it changes.
+func (s *server) validateEndpoints(start, end int, modelPath string) *validationError {
+	nodeCount := -1
+	if info, err := os.Stat(modelPath); err == nil {
+		if graph, err := s.modelGraphOf(modelPath, info.ModTime()); err == nil {
+			nodeCount = graph.Nodes
+		}
+	}
+	if err := validateNodeIndex("start", start, nodeCount); err != nil {
+		return err
+	}
+	return validateNodeIndex("end", end, nodeCount)
+}
+
+// listModels returns the supported model files in dir sorted by name. Hidden
+// files and directories are skipped; a missing dir yields an empty list.
+func listModels(dir string) ([]modelInfo, error) {
+	models := []modelInfo{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return models, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if checkModelExtension(name) != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		models = append(models, modelInfo{
+			Name:       name,
+			SizeBytes:  info.Size(),
+			ModifiedAt: info.ModTime().UTC(),
+		})
+	}
+
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+func (s *server) handleListModels(c *gin.Context) {
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+
+	models, err := listModels(s.modelDir(projectRoot))
+	if err != nil {
+		respondError(c, newAPIError(500, codeInternal, "failed to list models").wrap(err))
+		return
+	}
+	c.JSON(200, models)
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestModelGraphCountsOnlyVertexLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mesh.obj")
+	obj := "# comment\nv 0 0 0\nv 1 0 0\nvn 0 0 1\nvt 0 0\n  v 0 1 0\nf 1 2 3\n"
+	if err := os.WriteFile(path, []byte(obj), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+
+	got, err := readModelGraph(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Nodes != 3 {
+		t.Fatalf("expected 3 vertices, got %d", got.Nodes)
+	}
+}
+
+func TestModelGraphMissingFile(t *testing.T) {
+	if _, err := readModelGraph(filepath.Join(t.TempDir(), "missing.obj")); err == nil {
+		t.Fatalf("expected an error for a missing model")
+	}
+}
+
+func TestEndpointValidationReusesTheCachedVertexCount(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,9]}`), nil },
+	})
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":9,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Shrink the model but keep its modification time: the cached count
+	// still applies, so the file was not rescanned.
+	info, err := os.Stat(modelPath)
+	if err != nil {
+		t.Fatalf("failed to stat model: %v", err)
+	}
+	if err := os.WriteFile(modelPath, []byte("v 0 0 0\nv 1 0 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	if err := os.Chtimes(modelPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("failed to reset mtime: %v", err)
+	}
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":8,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected the cached count to accept end 8, got %d: %s", w.Code, w.Body.String())
+	}
+
+	later := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(modelPath, later, later); err != nil {
+		t.Fatalf("failed to bump mtime: %v", err)
+	}
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":7,"model":"mesh.obj"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected a changed model to be rescanned, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListModelsEndpointSortsAndFilters(t *testing.T) {
+	projectRoot := t.TempDir()
+	dataDir := filepath.Join(projectRoot, "frontend", "public", "data")
+	if err := os.MkdirAll(filepath.Join(dataDir, "nested.obj"), 0o755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+	for name, content := range map[string]string{
+		"sphere.obj":  "v 0 0 0\n",
+		"Bunny.OBJ":   "v 0 0 0\nv 1 1 1\n",
+		"donut.obj":   "",
+		".hidden.obj": "v 0 0 0\n",
+		"notes.txt":   "not a model",
+	} {
+		if err := os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/models", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var got []modelInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode models: %v", err)
+	}
+	names := make([]string, 0, len(got))
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	expected := []string{"Bunny.OBJ", "donut.obj", "sphere.obj"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected models %v, got %v", expected, names)
+	}
+	if got[0].SizeBytes != int64(len("v 0 0 0\nv 1 1 1\n")) {
+		t.Fatalf("expected size of Bunny.OBJ, got %d", got[0].SizeBytes)
+	}
+	if got[0].ModifiedAt.IsZero() {
+		t.Fatalf("expected modifiedAt to be set")
+	}
+}
+
+func TestListModelsEndpointReturnsEmptyArray(t *testing.T) {
+	projectRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectRoot, "frontend", "public", "data"), 0o755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/models", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Fatalf("expected empty JSON array, got %s", body)
+	}
+}
+
+func TestDataDirConfinesModelLookup(t *testing.T) {
+	projectRoot := t.TempDir()
+	dataDir := filepath.Join(projectRoot, "models")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+	// A model that exists outside DATA_DIR must never be reachable.
+	if err := os.WriteFile(filepath.Join(projectRoot, "secret.obj"), []byte("v 0 0 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write outside model: %v", err)
+	}
+	t.Setenv("DATA_DIR", "models")
+
+	for _, model := range []string{
+		"secret.obj",
+		"../secret.obj",
+		"..\\secret.obj",
+		filepath.Join(projectRoot, "secret.obj"),
+		"./../../secret.obj",
+	} {
+		t.Run(model, func(t *testing.T) {
+			var got []string
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return projectRoot, nil
+				},
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					got = append([]string(nil), args...)
+					return []byte(""), nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					return []byte(`{"path":[],"curves":[]}`), nil
+				},
+			})
+
+			body := `{"start":0,"end":0,"model":` + strconv.Quote(model) + `}`
+			w := performRequest(router, http.MethodPost, "/compute", body)
+
+			// The name is confined to DATA_DIR, where no secret.obj exists.
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected status 404, got %d", w.Code)
+			}
+			if got != nil {
+				t.Fatalf("runEngine should not be called for a model outside DATA_DIR, got %v", got)
+			}
+			if model := errorDetails(t, decodeJSONBody(t, w))["model"]; model != "secret.obj" {
+				t.Fatalf("expected sanitized model name, got %#v", model)
+			}
+		})
+	}
+}
+
+func TestListModelsUsesDataDir(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "plane.obj"), []byte("v 0 0 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	t.Setenv("DATA_DIR", dataDir)
+
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return t.TempDir(), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/models", "")
+	var got []modelInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode models: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "plane.obj" {
+		t.Fatalf("expected plane.obj from DATA_DIR, got %+v", got)
+	}
+}
+
+func TestMissingModelReturnsNotFound(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	dataDir := filepath.Join(projectRoot, "frontend", "public", "data")
+	if err := os.Mkdir(filepath.Join(dataDir, "folder.obj"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		model      string
+		wantStatus int
+	}{
+		{name: "existing file", model: "mesh.obj", wantStatus: http.StatusOK},
+		{name: "missing file", model: "missing.obj", wantStatus: http.StatusNotFound},
+		{name: "directory", model: "folder.obj", wantStatus: http.StatusNotFound},
+		{name: "traversal to existing file", model: "../data/../../../mesh.obj", wantStatus: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runCalled := false
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return projectRoot, nil
+				},
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					runCalled = true
+					return []byte(""), nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					return []byte(`{"path":[],"curves":[]}`), nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute",
+				`{"start":0,"end":1,"model":`+strconv.Quote(tc.model)+`}`)
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, w.Code)
+			}
+			if tc.wantStatus == http.StatusOK {
+				return
+			}
+
+			if runCalled {
+				t.Fatalf("runEngine should not be called for a missing model")
+			}
+			body := decodeJSONBody(t, w)
+			if body["code"] != string(codeModelNotFound) || body["message"] != "model not found" {
+				t.Fatalf("expected MODEL_NOT_FOUND, got %#v", body)
+			}
+			if got := errorDetails(t, body)["model"]; got != filepath.Base(tc.model) {
+				t.Fatalf("expected model %q in details, got %#v", filepath.Base(tc.model), got)
+			}
+		})
+	}
+}
+
+func TestComputeEnforcesModelExtensionAllowlist(t *testing.T) {
+	projectRoot := newTestProject(t, "Bunny.OBJ", 10)
+	for _, name := range []string{"notes.txt", "passwd"} {
+		if err := os.WriteFile(filepath.Join(projectRoot, "frontend", "public", "data", name), []byte("v 0 0 0\n"), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	engineCalls := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			engineCalls++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name   string
+		model  string
+		status int
+		code   errorCode
+	}{
+		{name: "accepted uppercase extension", model: "Bunny.OBJ", status: http.StatusOK},
+		{name: "rejected extension", model: "notes.txt", status: http.StatusUnsupportedMediaType, code: codeUnsupportedModel},
+		{name: "extensionless", model: "../../etc/passwd", status: http.StatusUnsupportedMediaType, code: codeUnsupportedModel},
+		{name: "empty", model: " ", status: http.StatusBadRequest, code: codeInvalidInput},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			engineCalls = 0
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"`+tc.model+`"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d (%s)", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			body := decodeJSONBody(t, w)
+			if body["code"] != string(tc.code) {
+				t.Fatalf("expected code %s, got %v", tc.code, body["code"])
+			}
+			if tc.code == codeUnsupportedModel && body["message"] != "unsupported model type, accepted: .csv, .obj" {
+				t.Fatalf("expected accepted types in message, got %v", body["message"])
+			}
+			if engineCalls != 0 {
+				t.Fatalf("expected the engine not to run for a rejected model")
+			}
+		})
+	}
+}
+
+func TestMaxModelBytesGuardsExistingModels(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	info, err := os.Stat(filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj"))
+	if err != nil {
+		t.Fatalf("failed to stat model: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		limit  int64
+		status int
+	}{
+		{name: "under limit", limit: info.Size(), status: http.StatusOK},
+		{name: "over limit", limit: info.Size() - 1, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("MAX_MODEL_BYTES", strconv.FormatInt(tc.limit, 10))
+			engineRuns := 0
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					engineRuns++
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			body := decodeJSONBody(t, w)
+			want := "model is " + strconv.FormatInt(info.Size(), 10) + " bytes, over the " +
+				strconv.FormatInt(tc.limit, 10) + " byte limit"
+			if body["code"] != string(codePayloadTooLarge) || body["message"] != want {
+				t.Fatalf("unexpected error body %v", body)
+			}
+			if engineRuns != 0 {
+				t.Fatalf("expected the engine not to run for an oversized model")
+			}
+		})
+	}
+}
+
+func TestEmptyModelIsRejectedBeforeTheEngine(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		status  int
+	}{
+		{name: "empty", content: "", status: http.StatusUnprocessableEntity},
+		{name: "whitespace only", content: " \n\t\r\n  \n", status: http.StatusUnprocessableEntity},
+		{name: "normal", content: "v 0 0 0\nv 1 0 0\nv 2 0 0\nv 3 0 0\nv 4 0 0\n", status: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectRoot := newTestProject(t, "mesh.obj", 0)
+			modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+			if err := os.WriteFile(modelPath, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("failed to write model: %v", err)
+			}
+			engineRuns := 0
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					engineRuns++
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,4]}`), nil },
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+			if tc.status == http.StatusOK {
+				return
+			}
+			if body := decodeJSONBody(t, w); body["code"] != string(codeEmptyModel) {
+				t.Fatalf("expected code %s, got %v", codeEmptyModel, body)
+			}
+			if engineRuns != 0 {
+				t.Fatalf("expected the engine not to run for an empty model")
+			}
+		})
+	}
+}
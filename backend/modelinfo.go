@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// modelGraph summarizes the graph the engine builds from a model: one node
+// per vertex and one undirected edge per distinct side of a face or line.
+type modelGraph struct {
+	Nodes    int  `json:"nodes"`
+	Edges    int  `json:"edges"`
+	Directed bool `json:"directed"`
+}
+
+// modelGraphCache remembers modelGraph per model path, invalidated when the
+// file's modification time changes.
+type modelGraphCache struct {
+	mu      sync.Mutex
+	entries map[string]modelGraphEntry
+}
+
+type modelGraphEntry struct {
+	modTime time.Time
+	graph   modelGraph
+}
+
+func newModelGraphCache() *modelGraphCache {
+	return &modelGraphCache{entries: map[string]modelGraphEntry{}}
+}
+
+func (c *modelGraphCache) get(path string, modTime time.Time) (modelGraph, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[path]
+	if !ok || !entry.modTime.Equal(modTime) {
+		return modelGraph{}, false
+	}
+	return entry.graph, true
+}
+
+func (c *modelGraphCache) put(path string, modTime time.Time, graph modelGraph) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = modelGraphEntry{modTime: modTime, graph: graph}
+}
+
+// readModelGraph counts the vertices and distinct edges of an OBJ file. Face
+// and line elements may use v, v/vt, v//vn or v/vt/vn references, and
+// negative indices count back from the latest vertex; references outside the
+// vertices read so far are ignored.
+func readModelGraph(modelPath string) (modelGraph, error) {
+	f, err := os.Open(modelPath)
+	if err != nil {
+		return modelGraph{}, err
+	}
+	defer f.Close()
+
+	nodes := 0
+	edges := map[[2]int]struct{}{}
+	addEdge := func(a, b int) {
+		if a == b {
+			return
+		}
+		if a > b {
+			a, b = b, a
+		}
+		edges[[2]int{a, b}] = struct{}{}
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		switch string(fields[0]) {
+		case "v":
+			nodes++
+		case "e":
+			// An edge-list edge: "e a b weight".
+			if len(fields) >= 3 {
+				a, okA := objVertexIndex(fields[1], nodes)
+				b, okB := objVertexIndex(fields[2], nodes)
+				if okA && okB {
+					addEdge(a, b)
+				}
+			}
+		case "f", "l":
+			var refs []int
+			for _, field := range fields[1:] {
+				if idx, ok := objVertexIndex(field, nodes); ok {
+					refs = append(refs, idx)
+				}
+			}
+			for i := 1; i < len(refs); i++ {
+				addEdge(refs[i-1], refs[i])
+			}
+			// Faces close back on their first vertex; polylines do not.
+			if string(fields[0]) == "f" && len(refs) > 2 {
+				addEdge(refs[len(refs)-1], refs[0])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return modelGraph{}, err
+	}
+	return modelGraph{Nodes: nodes, Edges: len(edges)}, nil
+}
+
+// objVertexIndex converts an OBJ vertex reference to a 0-based index.
+func objVertexIndex(ref []byte, nodes int) (int, bool) {
+	if slash := bytes.IndexByte(ref, '/'); slash >= 0 {
+		ref = ref[:slash]
+	}
+	n, err := strconv.Atoi(string(ref))
+	if err != nil || n == 0 {
+		return 0, false
+	}
+	idx := n - 1
+	if n < 0 {
+		idx = nodes + n
+	}
+	if idx < 0 || idx >= nodes {
+		return 0, false
+	}
+	return idx, true
+}
+
+// handleModelInfo reports the size of a model's graph so clients can show
+// the valid index range before computing.
+func (s *server) handleModelInfo(c *gin.Context) {
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+
+	name, path := s.resolveModel(projectRoot, c.Param("name"))
+	if apiErr := checkModelExtension(name); apiErr != nil {
+		respondError(c, apiErr.withDetail("model", name))
+		return
+	}
+	path, err = engineModelPath(name, path, s.decompressedModelLimit())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	info, err := statModel(name, path)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	graph, err := s.modelGraphOf(path, info.ModTime())
+	if err != nil {
+		respondError(c, newAPIError(500, codeInternal, "failed to read model").wrap(err))
+		return
+	}
+	c.JSON(200, graph)
+}
+
+// modelGraphOf returns the graph of the model at path, reading the file only
+// when modTime differs from the cached entry.
+func (s *server) modelGraphOf(path string, modTime time.Time) (modelGraph, error) {
+	if graph, ok := s.graphs.get(path, modTime); ok {
+		return graph, nil
+	}
+	graph, err := readModelGraph(path)
+	if err != nil {
+		return modelGraph{}, err
+	}
+	s.graphs.put(path, modTime, graph)
+	return graph, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const infoTestModel = `v 0 0 0
+v 1 0 0
+v 0 1 0
+v 0 0 1
+f 1 2 3
+f 1/1 3/3 4/4
+`
+
+func writeInfoTestModel(t *testing.T, root, contents string, modTime time.Time) {
+	t.Helper()
+	path := filepath.Join(root, "frontend", "public", "data", "tet.obj")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModelInfoCountsNodesAndEdges(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 1)
+	writeInfoTestModel(t, projectRoot, infoTestModel, time.Now().Add(-time.Hour))
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodGet, "/models/tet.obj/info", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body := decodeJSONBody(t, w)
+	if body["nodes"] != float64(4) || body["edges"] != float64(5) || body["directed"] != false {
+		t.Fatalf("unexpected model info: %v", body)
+	}
+
+	// A rewritten model must not be served from the cache.
+	writeInfoTestModel(t, projectRoot, infoTestModel+"v 1 1 1\nf 2 3 5\n", time.Now())
+	body = decodeJSONBody(t, performRequest(router, http.MethodGet, "/models/tet.obj/info", ""))
+	if body["nodes"] != float64(5) || body["edges"] != float64(7) {
+		t.Fatalf("expected refreshed model info, got %v", body)
+	}
+}
+
+func TestModelInfoUnknownModel(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 4)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodGet, "/models/missing.obj/info", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+	if body := decodeJSONBody(t, w); body["code"] != string(codeModelNotFound) {
+		t.Fatalf("expected MODEL_NOT_FOUND, got %v", body)
+	}
+}
+
+func TestModelInfoRejectsTraversal(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 4)
+	// A model outside the data directory that traversal would reach.
+	if err := os.WriteFile(filepath.Join(projectRoot, "frontend", "public", "passwd.obj"), []byte(infoTestModel), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodGet, "/models/..%5Cpasswd.obj/info", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if details := errorDetails(t, decodeJSONBody(t, w)); details["model"] != "passwd.obj" {
+		t.Fatalf("expected the sanitized model name, got %v", details)
+	}
+}
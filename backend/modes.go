@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// legacyResultFiles are the result files of the original engine modes, which
+// predate the <mode>_result.json convention.
+var legacyResultFiles = map[string]string{
+	"":          "result.json",
+	"dijkstra":  "result.json",
+	"analytics": "analytics.json",
+}
+
+// resultFileForMode returns the file the engine writes for mode inside its
+// output directory.
+func resultFileForMode(mode string) string {
+	if name, ok := legacyResultFiles[mode]; ok {
+		return name
+	}
+	return mode + "_result.json"
+}
+
+// astarHeuristics are the distance heuristics the engine's "astar" mode
+// accepts as its fifth argument.
+var astarHeuristics = []string{"euclidean", "manhattan"}
+
+// astarArgs forwards the requested heuristic to the engine, defaulting to
+// euclidean. The engine writes the path to astar_result.json using the same
+// shape as result.json.
+func astarArgs(req computeRequest) ([]string, error) {
+	heuristic := strings.ToLower(strings.TrimSpace(req.Heuristic))
+	if heuristic == "" {
+		heuristic = astarHeuristics[0]
+	}
+	for _, h := range astarHeuristics {
+		if heuristic == h {
+			return []string{heuristic}, nil
+		}
+	}
+	return nil, fmt.Errorf("heuristic must be one of %s, got %q",
+		strings.Join(astarHeuristics, ", "), req.Heuristic)
+}
+
+// K-shortest-paths bounds: the default number of alternatives and the most a
+// single request may ask for.
+const (
+	defaultKSPPaths = 3
+	maxKSPPaths     = 10
+)
+
+// kspArgs forwards the number of paths to the engine's "ksp" mode, which
+// writes an array of paths, shortest first, to ksp_result.json.
+func kspArgs(req computeRequest) ([]string, error) {
+	k := defaultKSPPaths
+	if req.K != nil {
+		k = *req.K
+	}
+	if k < 1 || k > maxKSPPaths {
+		return nil, fmt.Errorf("k must be between 1 and %d, got %d", maxKSPPaths, k)
+	}
+	return []string{strconv.Itoa(k)}, nil
+}
+
+// Heat kernel bounds. The engine diffuses for weight·(radius·h)², h being
+// the mesh's mean edge length, so radius is measured in edge lengths and
+// weight scales the time directly; when a request sets neither the engine's
+// own defaults apply, which match these.
+const (
+	defaultHeatRadius = 1.0
+	defaultHeatWeight = 1.0
+	maxHeatWeight     = 10.0
+)
+
+// heatArgs forwards the kernel radius and weight to the engine's "heat" mode
+// as two positional arguments. Either one being set forwards both, with the
+// default standing in for the other.
+func heatArgs(req computeRequest) ([]string, error) {
+	if req.Radius == nil && req.Weight == nil {
+		return nil, nil
+	}
+	radius, weight := defaultHeatRadius, defaultHeatWeight
+	if req.Radius != nil {
+		radius = *req.Radius
+	}
+	if req.Weight != nil {
+		weight = *req.Weight
+	}
+	if !(radius > 0) || math.IsInf(radius, 0) {
+		return nil, fmt.Errorf("radius must be greater than 0, got %v", radius)
+	}
+	if !(weight > 0 && weight <= maxHeatWeight) {
+		return nil, fmt.Errorf("weight must be greater than 0 and at most %v, got %v", maxHeatWeight, weight)
+	}
+	return []string{formatCoord(radius), formatCoord(weight)}, nil
+}
+
+// checkNegativeCycle rejects Bellman-Ford results in which the engine reported
+// a negative-weight cycle, since no shortest path exists.
+func checkNegativeCycle(payload []byte) error {
+	var result struct {
+		NegativeCycleDetected bool `json:"negativeCycleDetected"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil || !result.NegativeCycleDetected {
+		return nil
+	}
+	return newAPIError(422, codeNegativeCycle, "graph contains a negative-weight cycle").
+		withDetail("negativeCycleDetected", true)
+}
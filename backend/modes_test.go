@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResultFileForMode(t *testing.T) {
+	cases := []struct {
+		mode string
+		want string
+	}{
+		{mode: "", want: "result.json"},
+		{mode: "dijkstra", want: "result.json"},
+		{mode: "analytics", want: "analytics.json"},
+		{mode: "heat", want: "heat_result.json"},
+		{mode: "astar", want: "astar_result.json"},
+		{mode: "bfs", want: "bfs_result.json"},
+		{mode: "bellman", want: "bellman_result.json"},
+		{mode: "ksp", want: "ksp_result.json"},
+		{mode: "mst", want: "mst_result.json"},
+	}
+	for _, tc := range cases {
+		if got := resultFileForMode(tc.mode); got != tc.want {
+			t.Errorf("resultFileForMode(%q) = %q, want %q", tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestEveryRegisteredModeHasAResultFile(t *testing.T) {
+	owners := map[string]string{}
+	for name, route := range batchModes {
+		file := resultFileForMode(route.mode)
+		if !strings.HasSuffix(file, ".json") || strings.HasPrefix(file, "_") {
+			t.Errorf("mode %q has no usable result file: %q", name, file)
+		}
+		if owner, taken := owners[file]; taken && batchModes[owner].mode != route.mode {
+			t.Errorf("modes %q and %q both write %s", owner, name, file)
+		}
+		owners[file] = name
+	}
+}
+
+func TestPathModesAnswerSameNodeWithoutEngine(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	engineRuns := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			engineRuns++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	for _, route := range []string{"/compute", "/compute_astar_path", "/compute_bfs_path", "/compute_bellman_ford_path"} {
+		t.Run(route, func(t *testing.T) {
+			w := performRequest(router, http.MethodPost, route, `{"start":4,"end":4,"model":"mesh.obj"}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if want := `{"path":[4],"totalDistance":0,"reachable":true}`; w.Body.String() != want {
+				t.Fatalf("expected %s, got %s", want, w.Body.String())
+			}
+		})
+	}
+	if engineRuns != 0 {
+		t.Fatalf("expected no engine runs for same-node paths, got %d", engineRuns)
+	}
+
+	for _, route := range []string{"/analytics", "/heat"} {
+		engineRuns = 0
+		if w := performRequest(router, http.MethodPost, route, `{"start":4,"end":4,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", route, w.Code)
+		}
+		if engineRuns != 1 {
+			t.Fatalf("%s: expected the engine to run, got %d runs", route, engineRuns)
+		}
+	}
+}
+
+func TestAStarRouteForwardsHeuristic(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+	cases := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{name: "default", body: `{"start":1,"end":5,"model":"mesh.obj"}`,
+			expected: []string{"1", "5", modelPath, "astar", "euclidean"}},
+		{name: "manhattan", body: `{"start":1,"end":5,"model":"mesh.obj","heuristic":"Manhattan"}`,
+			expected: []string{"1", "5", modelPath, "astar", "manhattan"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got engineCall
+			readPath := ""
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return projectRoot, nil
+				},
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					got.args, got.outputDir = splitOutputDir(args)
+					return []byte(""), nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					readPath = path
+					return []byte(`{"path":[1,5]}`), nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute_astar_path", tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+			}
+			if strings.Join(got.args, "|") != strings.Join(tc.expected, "|") {
+				t.Fatalf("expected args %v, got %v", tc.expected, got.args)
+			}
+			expectedReadPath := filepath.Join(got.outputDir, "astar_result.json")
+			if readPath != expectedReadPath {
+				t.Fatalf("expected readFile path %q, got %q", expectedReadPath, readPath)
+			}
+		})
+	}
+}
+
+func TestAStarRouteRejectsUnknownHeuristic(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			t.Fatalf("runEngine should not be called for an unknown heuristic")
+			return nil, nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_astar_path",
+		`{"start":1,"end":5,"model":"mesh.obj","heuristic":"chebyshev"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if msg, _ := body["message"].(string); !strings.Contains(msg, "euclidean, manhattan") {
+		t.Fatalf("expected error listing heuristics, got %q", msg)
+	}
+}
+
+func TestBFSRouteAppendsModeArgument(t *testing.T) {
+	projectRoot := newTestProject(t, "grid.obj", 10)
+	modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "grid.obj")
+	var got engineCall
+	readPath := ""
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			readPath = path
+			return []byte(`{"path":[2,7]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_bfs_path", `{"start":2,"end":7,"model":"grid.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	expected := []string{"2", "7", modelPath, "bfs"}
+	if strings.Join(got.args, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected args %v, got %v", expected, got.args)
+	}
+	expectedReadPath := filepath.Join(got.outputDir, "bfs_result.json")
+	if readPath != expectedReadPath {
+		t.Fatalf("expected readFile path %q, got %q", expectedReadPath, readPath)
+	}
+}
+
+func TestBellmanFordRouteReportsNegativeCycles(t *testing.T) {
+	cases := []struct {
+		name    string
+		payload string
+		status  int
+	}{
+		{name: "shortest path", payload: `{"path":[0,2,4],"negativeCycleDetected":false}`, status: http.StatusOK},
+		{name: "negative cycle", payload: `{"path":[],"negativeCycleDetected":true}`, status: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectRoot := newTestProject(t, "market.obj", 10)
+			var got engineCall
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) {
+					return projectRoot, nil
+				},
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					got.args, got.outputDir = splitOutputDir(args)
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					if path != filepath.Join(got.outputDir, "bellman_result.json") {
+						t.Fatalf("unexpected result path %q", path)
+					}
+					return []byte(tc.payload), nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute_bellman_ford_path", `{"start":0,"end":4,"model":"market.obj"}`)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d (%s)", tc.status, w.Code, w.Body.String())
+			}
+			if len(got.args) != 4 || got.args[3] != "bellman" {
+				t.Fatalf("expected bellman mode argument, got %v", got.args)
+			}
+			if tc.status == http.StatusOK {
+				if w.Body.String() != tc.payload {
+					t.Fatalf("expected engine payload, got %s", w.Body.String())
+				}
+				return
+			}
+			body := decodeJSONBody(t, w)
+			if body["code"] != string(codeNegativeCycle) {
+				t.Fatalf("expected NEGATIVE_CYCLE, got %v", body["code"])
+			}
+			if errorDetails(t, body)["negativeCycleDetected"] != true {
+				t.Fatalf("expected negativeCycleDetected in details, got %v", body)
+			}
+		})
+	}
+}
+
+func TestKSPRouteForwardsModeAndK(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		k    string
+	}{
+		{name: "default", body: `{"start":2,"end":7,"model":"grid.obj"}`, k: "3"},
+		{name: "explicit", body: `{"start":2,"end":7,"model":"grid.obj","k":5}`, k: "5"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectRoot := newTestProject(t, "grid.obj", 10)
+			modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "grid.obj")
+			var got engineCall
+			readPath := ""
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					got.args, got.outputDir = splitOutputDir(args)
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) {
+					readPath = path
+					return []byte(`[{"path":[2,7]},{"path":[2,3,7]}]`), nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute_ksp", tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+			}
+			expected := []string{"2", "7", modelPath, "ksp", tc.k}
+			if strings.Join(got.args, "|") != strings.Join(expected, "|") {
+				t.Fatalf("expected args %v, got %v", expected, got.args)
+			}
+			if readPath != filepath.Join(got.outputDir, "ksp_result.json") {
+				t.Fatalf("expected ksp_result.json to be read, got %q", readPath)
+			}
+		})
+	}
+}
+
+func TestKSPRouteRejectsInvalidK(t *testing.T) {
+	for _, k := range []string{"0", "-2", "11"} {
+		t.Run(k, func(t *testing.T) {
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return newTestProject(t, "grid.obj", 10), nil },
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					t.Fatalf("runEngine should not be called for k=%s", k)
+					return nil, nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/compute_ksp", `{"start":2,"end":7,"model":"grid.obj","k":`+k+`}`)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestHeatRouteForwardsRadiusAndWeight(t *testing.T) {
+	cases := []struct {
+		name  string
+		route string
+		body  string
+		extra []string
+	}{
+		{name: "omitted", route: "/heat", body: `{"start":2,"end":7,"model":"mesh.obj"}`},
+		{name: "both", route: "/heat", body: `{"start":2,"end":7,"model":"mesh.obj","radius":2.5,"weight":0.5}`, extra: []string{"2.5", "0.5"}},
+		{name: "radius only", route: "/heat", body: `{"start":2,"end":7,"model":"mesh.obj","radius":3}`, extra: []string{"3", "1"}},
+		{name: "query", route: "/heat?start=2&end=7&model=mesh.obj&weight=4", extra: []string{"1", "4"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			modelPath := filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj")
+			var got engineCall
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					got.args, got.outputDir = splitOutputDir(args)
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(`{"curves":[]}`), nil },
+			})
+
+			method := http.MethodPost
+			if tc.body == "" {
+				method = http.MethodGet
+			}
+			w := performRequest(router, method, tc.route, tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+			}
+			expected := append([]string{"2", "7", modelPath, "heat"}, tc.extra...)
+			if strings.Join(got.args, "|") != strings.Join(expected, "|") {
+				t.Fatalf("expected args %v, got %v", expected, got.args)
+			}
+		})
+	}
+}
+
+func TestHeatRouteRejectsInvalidRadiusAndWeight(t *testing.T) {
+	for _, params := range []string{`"radius":0`, `"radius":-1`, `"weight":0`, `"weight":11`, `"radius":1,"weight":-0.5`} {
+		t.Run(params, func(t *testing.T) {
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+				runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+					t.Fatalf("runEngine should not be called for %s", params)
+					return nil, nil
+				},
+			})
+
+			w := performRequest(router, http.MethodPost, "/heat", `{"start":2,"end":7,"model":"mesh.obj",`+params+`}`)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestMSTRouteForwardsModeWithoutEndpoints(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	var readPath string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			readPath = path
+			return []byte(`{"edges":[[0,1],[1,2]],"totalWeight":2.5}`), nil
+		},
+	})
+
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodPost, "/compute_mst", `{"model":"mesh.obj"}`},
+		// Endpoints sent anyway are ignored rather than validated.
+		{http.MethodPost, "/compute_mst", `{"start":0,"end":99,"model":"mesh.obj"}`},
+		{http.MethodGet, "/compute_mst?model=mesh.obj", ""},
+	} {
+		w := performRequest(router, req.method, req.path, req.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: expected status 200, got %d: %s", req.method, req.body, w.Code, w.Body.String())
+		}
+	}
+	want := []string{"0", "0", filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj"), "mst"}
+	if strings.Join(got.args, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected args %v, got %v", want, got.args)
+	}
+	if readPath != filepath.Join(got.outputDir, "mst_result.json") {
+		t.Fatalf("expected mst_result.json to be read, got %s", readPath)
+	}
+
+	if w := performRequest(router, http.MethodPost, "/compute_mst", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected the model to stay required, got %d", w.Code)
+	}
+}
+
+func TestDiameterRouteForwardsModeWithOptionalEndpoints(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	var readPath string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			readPath = path
+			return []byte(`{"diameter":7.5,"endpoints":[0,9],"eccentricities":[7.5,6,5,4,5,6,6.5,7,7,7.5]}`), nil
+		},
+	})
+
+	for _, body := range []string{`{"model":"mesh.obj"}`, `{"start":3,"end":4,"model":"mesh.obj"}`} {
+		w := performRequest(router, http.MethodPost, "/compute_diameter", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", body, w.Code, w.Body.String())
+		}
+		result := decodeJSONBody(t, w)
+		if result["diameter"] != 7.5 || fmt.Sprint(result["endpoints"]) != "[0 9]" {
+			t.Fatalf("expected the diameter and its endpoints, got %v", result)
+		}
+	}
+	want := []string{"0", "0", filepath.Join(projectRoot, "frontend", "public", "data", "mesh.obj"), "diameter"}
+	if strings.Join(got.args, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected args %v, got %v", want, got.args)
+	}
+	if readPath != filepath.Join(got.outputDir, "diameter_result.json") {
+		t.Fatalf("expected diameter_result.json to be read, got %s", readPath)
+	}
+}
+
+func TestDirectedFlagIsForwardedOnlyWhenSet(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[1,2]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name, method, path, body string
+		directed                 bool
+	}{
+		{name: "omitted", method: http.MethodPost, path: "/compute", body: `{"start":1,"end":2,"model":"mesh.obj"}`},
+		{name: "false", method: http.MethodPost, path: "/compute", body: `{"start":1,"end":3,"model":"mesh.obj","directed":false}`},
+		{name: "true", method: http.MethodPost, path: "/compute", body: `{"start":1,"end":4,"model":"mesh.obj","directed":true}`, directed: true},
+		{name: "bfs", method: http.MethodPost, path: "/compute_bfs_path", body: `{"start":1,"end":2,"model":"mesh.obj","directed":true}`, directed: true},
+		{name: "query", method: http.MethodGet, path: "/compute?start=1&end=5&model=mesh.obj&directed=true", directed: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = engineCall{}
+			w := performRequest(router, tc.method, tc.path, tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if forwarded := slices.Contains(got.args, directedFlag); forwarded != tc.directed {
+				t.Fatalf("expected %s forwarded=%v, got args %v", directedFlag, tc.directed, got.args)
+			}
+		})
+	}
+
+	w := performRequest(router, http.MethodPost, "/heat", `{"start":1,"end":2,"model":"mesh.obj","directed":true}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected heat to reject directed with 400, got %d", w.Code)
+	}
+}
+
+func TestWeightedFlagIsForwardedOnlyForAnalytics(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"curves":[]}`), nil
+		},
+	})
+
+	cases := []struct {
+		name, method, path, body string
+		weighted                 bool
+	}{
+		{name: "omitted", method: http.MethodPost, path: "/analytics", body: `{"start":1,"end":2,"model":"mesh.obj"}`},
+		{name: "true", method: http.MethodPost, path: "/analytics", body: `{"start":1,"end":3,"model":"mesh.obj","weighted":true}`, weighted: true},
+		{name: "query", method: http.MethodGet, path: "/analytics?start=1&end=4&model=mesh.obj&weighted=true", weighted: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = engineCall{}
+			w := performRequest(router, tc.method, tc.path, tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if forwarded := slices.Contains(got.args, weightedFlag); forwarded != tc.weighted {
+				t.Fatalf("expected %s forwarded=%v, got args %v", weightedFlag, tc.weighted, got.args)
+			}
+		})
+	}
+
+	for _, path := range []string{"/compute", "/compute_bfs_path", "/heat"} {
+		w := performRequest(router, http.MethodPost, path, `{"start":1,"end":2,"model":"mesh.obj","weighted":true}`)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected weighted to be rejected with 400, got %d", path, w.Code)
+		}
+		if details := errorDetails(t, decodeJSONBody(t, w)); details["field"] != "weighted" {
+			t.Fatalf("%s: expected the weighted field in details, got %v", path, details)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxMultiModels = 20
+
+type multiRequest struct {
+	Models    []string `json:"models"`
+	Start     *int     `json:"start"`
+	End       *int     `json:"end"`
+	Mode      string   `json:"mode"`
+	Heuristic string   `json:"heuristic,omitempty"`
+	K         *int     `json:"k,omitempty"`
+	Radius    *float64 `json:"radius,omitempty"`
+	Weight    *float64 `json:"weight,omitempty"`
+	Directed  bool     `json:"directed,omitempty"`
+	Weighted  bool     `json:"weighted,omitempty"`
+	Engine    string   `json:"engine,omitempty"`
+}
+
+// handleMulti runs the same start/end on several models concurrently and
+// returns a map from each requested model name to its outcome. Every name is
+// resolved on its own, so one bad name only fails its own entry.
+func (s *server) handleMulti(c *gin.Context) {
+	var req multiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errRequestBody(err))
+		return
+	}
+	route, ok := batchModes[strings.ToLower(strings.TrimSpace(req.Mode))]
+	if !ok {
+		respondError(c, newAPIError(400, codeInvalidInput,
+			fmt.Sprintf("unsupported mode %q", req.Mode)))
+		return
+	}
+
+	models := make([]string, 0, len(req.Models))
+	seen := make(map[string]bool, len(req.Models))
+	for _, model := range req.Models {
+		if !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 || len(models) > maxMultiModels {
+		respondError(c, newAPIError(400, codeInvalidInput,
+			fmt.Sprintf("models must contain between 1 and %d distinct entries", maxMultiModels)).
+			withDetail("models", len(models)))
+		return
+	}
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+
+	requestID := requestIDFrom(c)
+	outcomes := make([]outcome, len(models))
+	s.forEachConcurrently(len(models), func(i int) {
+		single := computeRequest{
+			Start:     req.Start,
+			End:       req.End,
+			Model:     models[i],
+			Heuristic: req.Heuristic,
+			K:         req.K,
+			Radius:    req.Radius,
+			Weight:    req.Weight,
+			Directed:  req.Directed,
+			Weighted:  req.Weighted,
+			Engine:    req.Engine,
+		}
+		outcomes[i] = s.computeOutcome(c.Request.Context(), requestID, projectRoot, single, route)
+	})
+
+	results := make(map[string]outcome, len(models))
+	for i, model := range models {
+		results[model] = outcomes[i]
+	}
+	body, err := json.Marshal(results)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	writePayload(c, http.StatusOK, body)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiComputesEachModelIndependently(t *testing.T) {
+	projectRoot := newTestProject(t, "bunny.obj", 10)
+	dataDir := filepath.Join(projectRoot, "frontend", "public", "data")
+	if err := os.WriteFile(filepath.Join(dataDir, "sphere.obj"), []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_multi", `{
+		"models": ["bunny.obj", "sphere.obj", "../../etc/passwd", "ghost.obj", "notes.txt", "bunny.obj"],
+		"start": 0,
+		"end": 5,
+		"mode": "heat"
+	}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	var results map[string]outcome
+	if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+		t.Fatalf("failed to decode results: %v", err)
+	}
+	expected := map[string]struct {
+		status int
+		code   errorCode
+	}{
+		"bunny.obj": {status: http.StatusOK},
+		// sphere.obj only has 3 vertices, so end=5 is out of range for it.
+		"sphere.obj":       {status: http.StatusUnprocessableEntity, code: codeInvalidInput},
+		"../../etc/passwd": {status: http.StatusUnsupportedMediaType, code: codeUnsupportedModel},
+		"ghost.obj":        {status: http.StatusNotFound, code: codeModelNotFound},
+		"notes.txt":        {status: http.StatusUnsupportedMediaType, code: codeUnsupportedModel},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d distinct results, got %d: %s", len(expected), len(results), w.Body.String())
+	}
+	for model, want := range expected {
+		got, ok := results[model]
+		if !ok {
+			t.Fatalf("missing result for %q", model)
+		}
+		if got.Status != want.status {
+			t.Fatalf("%s: expected status %d, got %+v", model, want.status, got)
+		}
+		if want.code == "" {
+			if string(got.Result) != `{"path":[],"curves":[]}` {
+				t.Fatalf("%s: expected inline payload, got %s", model, got.Result)
+			}
+			continue
+		}
+		if got.Error == nil || got.Error.Code != want.code {
+			t.Fatalf("%s: expected error %s, got %+v", model, want.code, got)
+		}
+	}
+}
+
+func TestMultiRejectsBadRequests(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+	})
+	names := make([]string, maxMultiModels+1)
+	for i := range names {
+		names[i] = fmt.Sprintf(`"m%d.obj"`, i)
+	}
+
+	cases := map[string]string{
+		"no models":    `{"models":[],"start":0,"end":1}`,
+		"too many":     `{"models":[` + strings.Join(names, ",") + `],"start":0,"end":1}`,
+		"unknown mode": `{"models":["mesh.obj"],"start":0,"end":1,"mode":"warp"}`,
+	}
+	for name, body := range cases {
+		if w := performRequest(router, http.MethodPost, "/compute_multi", body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// totalCountHeader carries the length of a paginated result array before
+// slicing.
+const totalCountHeader = "X-Total-Count"
+
+// page is an optional ?offset=&limit= window over a result array. A zero
+// limit means everything from offset on.
+type page struct {
+	set    bool
+	offset int
+	limit  int
+}
+
+// parsePage reads ?offset= and ?limit=, both non-negative integers.
+func parsePage(c *gin.Context) (page, error) {
+	var p page
+	for _, param := range []struct {
+		key string
+		dst *int
+	}{{"offset", &p.offset}, {"limit", &p.limit}} {
+		raw, ok := c.GetQuery(param.key)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || n < 0 {
+			return page{}, newAPIError(400, codeInvalidInput,
+				fmt.Sprintf("query parameter %q must be a non-negative integer, got %q", param.key, raw)).
+				withDetail("field", param.key)
+		}
+		*param.dst = n
+		p.set = true
+	}
+	return p, nil
+}
+
+// apply slices payload when it is a top-level JSON array and reports the full
+// length in X-Total-Count. Other payloads are returned untouched. An offset
+// past the end yields an empty array.
+func (p page) apply(c *gin.Context, payload []byte) []byte {
+	if !p.set || !bytes.HasPrefix(bytes.TrimLeft(payload, " \t\r\n"), []byte("[")) {
+		return payload
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(payload, &items); err != nil {
+		return payload
+	}
+	c.Header(totalCountHeader, strconv.Itoa(len(items)))
+
+	start := min(p.offset, len(items))
+	end := len(items)
+	if p.limit > 0 {
+		end = min(start+p.limit, end)
+	}
+	sliced, err := json.Marshal(items[start:end])
+	if err != nil {
+		return payload
+	}
+	return sliced
+}
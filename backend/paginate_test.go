@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func newHeatRouter(t *testing.T, result string) http.Handler {
+	t.Helper()
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(result), nil },
+	})
+}
+
+func TestHeatPagination(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "window", query: "offset=1&limit=2", want: `[1,2]`},
+		{name: "offset only", query: "offset=3", want: `[3,4]`},
+		{name: "limit past end", query: "offset=4&limit=10", want: `[4]`},
+		{name: "offset past end", query: "offset=50&limit=2", want: `[]`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newHeatRouter(t, `[0, 1, 2, 3, 4]`)
+			w := performRequest(router, http.MethodPost, "/heat?"+tc.query, `{"start":0,"end":1,"model":"mesh.obj"}`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if w.Body.String() != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, w.Body.String())
+			}
+			if got := w.Header().Get(totalCountHeader); got != "5" {
+				t.Fatalf("expected %s: 5, got %q", totalCountHeader, got)
+			}
+		})
+	}
+}
+
+func TestHeatPaginationLeavesObjectResultsAlone(t *testing.T) {
+	router := newHeatRouter(t, `{"curves":[[0,1]]}`)
+	w := performRequest(router, http.MethodGet, "/heat?model=mesh.obj&start=0&end=1&offset=1&limit=1", "")
+	if w.Code != http.StatusOK || w.Body.String() != `{"curves":[[0,1]]}` {
+		t.Fatalf("expected the object result unchanged, got %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(totalCountHeader); got != "" {
+		t.Fatalf("expected no %s for an object result, got %q", totalCountHeader, got)
+	}
+}
+
+func TestHeatPaginationRejectsInvalidParams(t *testing.T) {
+	router := newHeatRouter(t, `[0]`)
+	w := performRequest(router, http.MethodPost, "/heat?offset=-1", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+	if details := errorDetails(t, decodeJSONBody(t, w)); details["field"] != "offset" {
+		t.Fatalf("expected offset to be named, got %v", details)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+const pprofPrefix = "/debug/pprof"
+
+// registerPprof mounts the net/http/pprof handlers when ENABLE_PPROF=true.
+// They reveal command lines and memory contents, so they need the API key on
+// every method when one is set. They skip rate limiting, since a CPU profile
+// holds its request open for the whole sampling period.
+func (s *server) registerPprof(r *gin.Engine) {
+	g := r.Group(pprofPrefix, s.requireAPIKey())
+	g.GET("/", gin.WrapF(pprof.Index))
+	g.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	g.GET("/profile", gin.WrapF(pprof.Profile))
+	g.GET("/symbol", gin.WrapF(pprof.Symbol))
+	g.POST("/symbol", gin.WrapF(pprof.Symbol))
+	g.GET("/trace", gin.WrapF(pprof.Trace))
+	// Index serves the named runtime profiles: heap, goroutine, block, ...
+	g.GET("/:profile", gin.WrapF(pprof.Index))
+}
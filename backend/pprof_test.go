@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getPprof(router http.Handler, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if key != "" {
+		req.Header.Set(apiKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPprofIsOffByDefault(t *testing.T) {
+	router := newTestRouter(appDeps{})
+	for _, path := range []string{pprofPrefix + "/", pprofPrefix + "/goroutine"} {
+		if w := getPprof(router, path, ""); w.Code != http.StatusNotFound {
+			t.Fatalf("expected %s to be absent, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestPprofIsServedWhenEnabled(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	router := newTestRouter(appDeps{})
+	for _, path := range []string{pprofPrefix + "/", pprofPrefix + "/goroutine?debug=1", pprofPrefix + "/cmdline"} {
+		if w := getPprof(router, path, ""); w.Code != http.StatusOK {
+			t.Fatalf("expected %s to be served, got %d: %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPprofRequiresAPIKeyWhenSet(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	t.Setenv("API_KEY", "s3cret")
+	router := newTestRouter(appDeps{})
+	if w := getPprof(router, pprofPrefix+"/goroutine", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without the key, got %d", w.Code)
+	}
+	if w := getPprof(router, pprofPrefix+"/goroutine", "s3cret"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with the key, got %d", w.Code)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// configureProcessGroup is a no-op where process groups are unavailable;
+// exec.CommandContext still kills the engine process itself.
+func configureProcessGroup(cmd *exec.Cmd) {}
+
+// niceCommand and lowerPriority are no-ops where process priorities cannot be
+// set portably, so ENGINE_NICE is ignored.
+func niceCommand(cmd *exec.Cmd, nice int) bool { return false }
+
+func lowerPriority(cmd *exec.Cmd, nice int) {}
+
+// exitSignal always returns "" since only Unix reports terminating signals.
+func exitSignal(exitErr *exec.ExitError) string { return "" }
@@ -0,0 +1,76 @@
+//go:build unix
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// configureProcessGroup starts the engine in its own process group so that a
+// cancelled context kills the engine and anything it spawned.
+func configureProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// niceCommand rewrites cmd to exec the engine through nice(1), so it runs at
+// the lower priority from its first instruction. Go's SysProcAttr has no
+// niceness field, and setting it after Start would leave the engine at normal
+// priority until the call lands. nice(1) execs the engine in place, keeping
+// its PID, process group and exit status. It returns false, leaving cmd
+// alone, when nice(1) is unavailable or the engine binary is missing, so
+// that a missing engine is still reported as such.
+func niceCommand(cmd *exec.Cmd, nice int) bool {
+	if nice <= 0 || cmd.Err != nil {
+		return false
+	}
+	nicePath, err := exec.LookPath("nice")
+	if err != nil {
+		return false
+	}
+	enginePath := cmd.Path
+	if !filepath.IsAbs(enginePath) {
+		enginePath = filepath.Join(cmd.Dir, enginePath)
+	}
+	if _, err := os.Stat(enginePath); err != nil {
+		return false
+	}
+	cmd.Args = append([]string{"nice", "-n", strconv.Itoa(nice), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = nicePath
+	return true
+}
+
+// lowerPriority is the fallback for when niceCommand could not wrap cmd: it
+// raises the niceness of the started engine's process group, which the engine
+// leads. The engine runs at normal priority until this lands, and a failure
+// leaves it there.
+func lowerPriority(cmd *exec.Cmd, nice int) {
+	if nice <= 0 {
+		return
+	}
+	if err := unix.Setpriority(unix.PRIO_PGRP, cmd.Process.Pid, nice); err != nil {
+		slog.Debug("could not lower engine priority", "pid", cmd.Process.Pid, "nice", nice, "error", err.Error())
+	}
+}
+
+// exitSignal names the signal that terminated the engine, such as "SIGSEGV",
+// or returns "" when it exited normally.
+func exitSignal(exitErr *exec.ExitError) string {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	if name := unix.SignalName(status.Signal()); name != "" {
+		return name
+	}
+	return status.Signal().String()
+}
@@ -0,0 +1,254 @@
+//go:build unix
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDefaultRunEngineKillsProcessGroupOnTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	// The backgrounded sleep inherits stdout; it must die with the group or
+	// CombinedOutput would block until it exits.
+	_, err := defaultRunEngine(ctx, t.TempDir(), "/bin/sh", "-c", "sleep 5 & sleep 5")
+	if err == nil {
+		t.Fatalf("expected an error from a killed engine")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("expected engine to be killed promptly, took %s", elapsed)
+	}
+}
+
+func TestEngineNiceLowersEnginePriority(t *testing.T) {
+	for _, tool := range []string{"nice", "ps"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not available", tool)
+		}
+	}
+	ctx := withEngineNice(context.Background(), 7)
+	// The engine starts already niced, so its first read sees the value.
+	script := "ps -o ni= -p $$"
+	for name, run := range map[string]func() ([]byte, error){
+		"run": func() ([]byte, error) { return defaultRunEngine(ctx, t.TempDir(), "/bin/sh", "-c", script) },
+		"stream": func() ([]byte, error) {
+			var out bytes.Buffer
+			_, err := defaultStreamEngine(ctx, t.TempDir(), "/bin/sh", func(line string) { out.WriteString(line) }, "-c", script)
+			return out.Bytes(), err
+		},
+	} {
+		out, err := run()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if got := strings.TrimSpace(string(out)); got != "7" {
+			t.Fatalf("%s: expected the engine to run at nice 7, got %q", name, got)
+		}
+	}
+}
+
+func TestNiceCommandWrapsTheEngineBeforeItStarts(t *testing.T) {
+	nicePath, err := exec.LookPath("nice")
+	if err != nil {
+		t.Skip("nice is not available")
+	}
+	cmd := exec.Command("/bin/sh", "-c", "true")
+	configureProcessGroup(cmd)
+	if !niceCommand(cmd, 7) {
+		t.Fatalf("expected the engine to be wrapped in nice")
+	}
+	if cmd.Path != nicePath || strings.Join(cmd.Args, " ") != "nice -n 7 /bin/sh -c true" {
+		t.Fatalf("unexpected command %s %q", cmd.Path, cmd.Args)
+	}
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid {
+		t.Fatalf("expected the process group to survive wrapping, got %+v", cmd.SysProcAttr)
+	}
+
+	for _, tc := range []struct {
+		name string
+		cmd  *exec.Cmd
+		nice int
+	}{
+		{name: "nice 0", cmd: exec.Command("/bin/sh"), nice: 0},
+		{name: "missing engine", cmd: exec.Command(filepath.Join(t.TempDir(), "engine")), nice: 7},
+	} {
+		args := slices.Clone(tc.cmd.Args)
+		if niceCommand(tc.cmd, tc.nice) || !slices.Equal(tc.cmd.Args, args) {
+			t.Fatalf("%s: expected the command to be left alone, got %q", tc.name, tc.cmd.Args)
+		}
+	}
+}
+
+func TestDefaultStreamEngineDeliversLinesAsPrinted(t *testing.T) {
+	var lines []string
+	stderr, err := defaultStreamEngine(context.Background(), t.TempDir(), "/bin/sh",
+		func(line string) { lines = append(lines, line) },
+		"-c", "echo 'PROGRESS 50'; echo oops >&2; echo 'PROGRESS 100'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(lines, "|") != "PROGRESS 50|PROGRESS 100" {
+		t.Fatalf("unexpected stdout lines %v", lines)
+	}
+	if strings.TrimSpace(string(stderr)) != "oops" {
+		t.Fatalf("expected stderr to be returned separately, got %q", stderr)
+	}
+}
+
+// processAlive reports whether pid is running; zombies awaiting a reaper
+// count as dead.
+func processAlive(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	// The state follows the parenthesised command name.
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
+
+// waitForEnginePIDs returns the PIDs the "hang" fake engine records once it
+// and its grandchild are running.
+func waitForEnginePIDs(t *testing.T, pidFile string) []int {
+	t.Helper()
+	var pids []int
+	deadline := time.Now().Add(5 * time.Second)
+	for len(pids) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("fake engine never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+		raw, _ := os.ReadFile(pidFile)
+		pids = pids[:0]
+		for _, field := range strings.Fields(string(raw)) {
+			if pid, err := strconv.Atoi(field); err == nil {
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids
+}
+
+func TestClientDisconnectKillsEngineProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pids")
+	t.Setenv("FAKE_ENGINE_PIDFILE", pidFile)
+	fakeEngineCommand(t, "hang")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodPost, "/compute", strings.NewReader(`{"start":0,"end":4,"model":"mesh.obj"}`)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		router.ServeHTTP(w, req)
+	}()
+
+	pids := waitForEnginePIDs(t, pidFile)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler did not return after the client disconnected")
+	}
+	if w.Code != statusClientClosedRequest {
+		t.Fatalf("expected status %d, got %d", statusClientClosedRequest, w.Code)
+	}
+	for _, pid := range pids {
+		for deadline := time.Now().Add(2 * time.Second); processAlive(pid); {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected process %d to be killed", pid)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestEngineKilledBySignalIsReportedAsCrash(t *testing.T) {
+	fakeEngineCommand(t, "crash")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+	body := decodeJSONBody(t, w)
+	if body["code"] != string(codeEngineCrashed) {
+		t.Fatalf("expected code %s, got %v", codeEngineCrashed, body["code"])
+	}
+	details := errorDetails(t, body)
+	if details["signal"] != "SIGKILL" {
+		t.Fatalf("expected signal SIGKILL in details, got %v", details["signal"])
+	}
+	if _, ok := details["exitCode"]; ok {
+		t.Fatalf("expected no exitCode for a signalled engine, got %v", details["exitCode"])
+	}
+	if body["message"] != "engine terminated by SIGKILL" {
+		t.Fatalf("unexpected message %q", body["message"])
+	}
+}
+
+func TestEngineKilledByShutdownIsNotACrash(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pids")
+	t.Setenv("FAKE_ENGINE_PIDFILE", pidFile)
+	t.Setenv("BREAKER_THRESHOLD", "1")
+	fakeEngineCommand(t, "hang")
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	gin.SetMode(gin.TestMode)
+	s := newServer(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+	})
+	router := s.router()
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":4,"model":"mesh.obj"}`)
+	}()
+	waitForEnginePIDs(t, pidFile)
+	// What drain does once the grace period expires.
+	s.engines.cancel()
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handler did not return after shutdown killed the engine")
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := decodeJSONBody(t, w); body["code"] != string(codeCanceled) {
+		t.Fatalf("expected code %s, got %v", codeCanceled, body["code"])
+	}
+	if ok, _, _ := s.breaker.allow(); !ok {
+		t.Fatalf("an engine killed by shutdown must not open the breaker")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func bindComputeJSON(c *gin.Context) (computeRequest, error) {
+	var req computeRequest
+	err := c.ShouldBindJSON(&req)
+	return req, err
+}
+
+// bindComputeQuery builds a computeRequest from ?model=&start=&end= (plus the
+// optional heuristic, k, radius, weight, directed, weighted and engine).
+// start and end are required integers.
+func bindComputeQuery(c *gin.Context) (computeRequest, error) {
+	start, err := queryInt(c, "start")
+	if err != nil {
+		return computeRequest{}, err
+	}
+	end, err := queryInt(c, "end")
+	if err != nil {
+		return computeRequest{}, err
+	}
+	req := computeRequest{
+		Start:     &start,
+		End:       &end,
+		Model:     c.Query("model"),
+		Heuristic: c.Query("heuristic"),
+		Engine:    c.Query("engine"),
+	}
+	if req.Directed, err = optionalQueryBool(c, "directed"); err != nil {
+		return computeRequest{}, err
+	}
+	if req.Weighted, err = optionalQueryBool(c, "weighted"); err != nil {
+		return computeRequest{}, err
+	}
+	if _, ok := c.GetQuery("k"); ok {
+		k, err := queryInt(c, "k")
+		if err != nil {
+			return computeRequest{}, err
+		}
+		req.K = &k
+	}
+	if req.Radius, err = optionalQueryFloat(c, "radius"); err != nil {
+		return computeRequest{}, err
+	}
+	if req.Weight, err = optionalQueryFloat(c, "weight"); err != nil {
+		return computeRequest{}, err
+	}
+	return req, nil
+}
+
+// bindModelQuery reads the query string of a whole-graph route, which only
+// names the model and optionally the engine.
+func bindModelQuery(c *gin.Context) (computeRequest, error) {
+	return computeRequest{Model: c.Query("model"), Engine: c.Query("engine")}, nil
+}
+
+func queryInt(c *gin.Context, key string) (int, error) {
+	raw, ok := c.GetQuery(key)
+	raw = strings.TrimSpace(raw)
+	if !ok || raw == "" {
+		return 0, fmt.Errorf("missing query parameter %q", key)
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("query parameter %q must be an integer, got %q", key, raw)
+	}
+	return n, nil
+}
+
+// optionalQueryBool parses ?key= as a boolean, which is false when absent.
+func optionalQueryBool(c *gin.Context, key string) (bool, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		return false, fmt.Errorf("query parameter %q must be true or false, got %q", key, raw)
+	}
+	return v, nil
+}
+
+// parseQueryFlag reads a response-shaping flag such as ?envelope=, which is
+// off unless set to a true value.
+func parseQueryFlag(c *gin.Context, key string) (bool, error) {
+	on, err := optionalQueryBool(c, key)
+	if err != nil {
+		return false, newAPIError(400, codeInvalidInput, err.Error()).withDetail("field", key)
+	}
+	return on, nil
+}
+
+// optionalQueryFloat parses ?key= as a number, returning nil when it is
+// absent.
+func optionalQueryFloat(c *gin.Context, key string) (*float64, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return nil, fmt.Errorf("query parameter %q must be a number, got %q", key, raw)
+	}
+	return &v, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGetComputeReadsQueryParameters(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	var got engineCall
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			got.args, got.outputDir = splitOutputDir(args)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[1,5]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodGet, "/compute_astar_path?model=mesh.obj&start=1&end=5&heuristic=manhattan", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w.Body.String() != `{"path":[1,5]}` {
+		t.Fatalf("unexpected body %s", w.Body.String())
+	}
+	if len(got.args) != 5 || got.args[0] != "1" || got.args[1] != "5" || got.args[4] != "manhattan" {
+		t.Fatalf("expected query values to reach the engine, got %v", got.args)
+	}
+}
+
+func TestGetComputeRejectsMalformedQuery(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+		runEngine: func(_ context.Context, projectRootArg, enginePath string, args ...string) ([]byte, error) {
+			t.Fatalf("runEngine should not be called for a malformed query")
+			return nil, nil
+		},
+	})
+
+	cases := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{name: "missing start", query: "model=mesh.obj&end=5", message: `missing query parameter "start"`},
+		{name: "empty end", query: "model=mesh.obj&start=1&end=", message: `missing query parameter "end"`},
+		{name: "non-integer", query: "model=mesh.obj&start=one&end=5", message: `"start" must be an integer, got "one"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodGet, "/compute?"+tc.query, "")
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+			body := decodeJSONBody(t, w)
+			if msg, _ := body["message"].(string); !strings.Contains(msg, tc.message) {
+				t.Fatalf("expected message containing %q, got %q", tc.message, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRateLimit = 10
+	defaultRateBurst = 20
+
+	// rateLimiterSweepInterval is how often buckets of idle clients are dropped.
+	rateLimiterSweepInterval = time.Minute
+)
+
+// rateLimitExempt lists paths that health checkers and scrapers poll.
+var rateLimitExempt = map[string]bool{
+	"/health":   true,
+	"/livez":    true,
+	"/readyz":   true,
+	metricsPath: true,
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a per-key token bucket: each key may make burst requests at
+// once, refilled at rate per second.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter(rate, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(rate),
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token for key. When none is left it reports how long until
+// the next one becomes available.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= rateLimiterSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep forgets clients whose buckets would have refilled completely, since a
+// fresh bucket is equivalent.
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// rateLimit rejects clients that exceed RATE_LIMIT requests per second (with
+// RATE_BURST headroom) with 429. RATE_LIMIT=0 disables limiting.
+func (s *server) rateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if s.limiter == nil || rateLimitExempt[path] || strings.HasPrefix(path, pprofPrefix+"/") {
+			c.Next()
+			return
+		}
+		if ok, wait := s.limiter.allow(c.ClientIP()); !ok {
+			respondError(c, newAPIError(429, codeRateLimited, "too many requests, slow down").
+				withRetryAfter(wait))
+			return
+		}
+		c.Next()
+	}
+}
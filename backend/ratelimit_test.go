@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterRefillsOverTime(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("request %d should fit in the burst", i)
+		}
+	}
+	ok, wait := l.allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected rejection with 500ms wait, got ok=%v wait=%s", ok, wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Fatalf("other clients have their own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Fatalf("expected a token after refilling")
+	}
+
+	now = now.Add(time.Hour)
+	l.allow("c")
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatalf("expected idle buckets to be swept")
+	}
+}
+
+func TestRateLimitReturnsTooManyRequests(t *testing.T) {
+	t.Setenv("RATE_LIMIT", "1")
+	t.Setenv("RATE_BURST", "2")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+	})
+
+	send := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var limited *httptest.ResponseRecorder
+	for i := 0; i < 5 && limited == nil; i++ {
+		if w := send("/models", "198.51.100.7:4000"); w.Code == http.StatusTooManyRequests {
+			limited = w
+		}
+	}
+	if limited == nil {
+		t.Fatalf("expected a 429 after exceeding the burst")
+	}
+	if limited.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected Retry-After: 1, got %q", limited.Header().Get("Retry-After"))
+	}
+	if body := decodeJSONBody(t, limited); body["code"] != string(codeRateLimited) {
+		t.Fatalf("expected RATE_LIMITED, got %v", body["code"])
+	}
+
+	if w := send("/health", "198.51.100.7:4000"); w.Code == http.StatusTooManyRequests {
+		t.Fatalf("expected /health to be exempt")
+	}
+	if w := send("/models", "203.0.113.9:4000"); w.Code != http.StatusOK {
+		t.Fatalf("expected another client to be unaffected, got %d", w.Code)
+	}
+}
+
+func TestRateLimitCanBeDisabled(t *testing.T) {
+	t.Setenv("RATE_LIMIT", "0")
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 10), nil
+		},
+	})
+	for i := 0; i < defaultRateBurst*2; i++ {
+		if w := performRequest(router, http.MethodGet, "/models", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200 with limiting disabled, got %d", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultModelFetchTimeout = 30 * time.Second
+
+// remoteModelDirName is the directory under the system temp dir that holds
+// downloaded models.
+const remoteModelDirName = "geodesic-remote-models"
+
+// isRemoteModel reports whether model names an http(s) URL rather than a
+// file in the data directory.
+func isRemoteModel(model string) bool {
+	lower := strings.ToLower(strings.TrimSpace(model))
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// maxModelFetchRedirects bounds how many redirects a download follows.
+const maxModelFetchRedirects = 5
+
+// errModelHostBlocked marks a download refused because its host is not in
+// MODEL_FETCH_HOSTS or resolves to a private address.
+var errModelHostBlocked = errors.New("remote model host is not allowed")
+
+// remoteModels downloads models named by URL into a local cache. Each cached
+// copy keeps the ETag and Last-Modified it was served with, so later requests
+// revalidate it instead of downloading it again.
+//
+// Only hosts listed in MODEL_FETCH_HOSTS are fetched from, and connections to
+// loopback, private and link-local addresses are refused after DNS resolution
+// unless MODEL_FETCH_ALLOW_PRIVATE is set, so a request cannot make the
+// server probe its own network. Both checks also apply to every redirect.
+type remoteModels struct {
+	dir          string
+	client       *http.Client
+	maxBytes     int64
+	hosts        map[string]bool
+	allowPrivate bool
+	locks        sync.Map // cache key -> *sync.Mutex
+}
+
+// remoteModelMeta is stored next to each cached model.
+type remoteModelMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func newRemoteModels(dir string, timeout time.Duration, maxBytes int64, hosts map[string]bool, allowPrivate bool) *remoteModels {
+	r := &remoteModels{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		hosts:        hosts,
+		allowPrivate: allowPrivate,
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: r.checkDialAddress}
+	r.client = &http.Client{
+		Timeout: timeout,
+		// No proxy: the dial check must see the address actually connected to.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxModelFetchRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxModelFetchRedirects)
+			}
+			return r.checkURL(req.URL)
+		},
+	}
+	return r
+}
+
+// checkURL rejects URLs that are not http(s) on an allowed host.
+func (r *remoteModels) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errModelHostBlocked
+	}
+	if !r.hosts[strings.ToLower(u.Hostname())] {
+		return errModelHostBlocked
+	}
+	return nil
+}
+
+// checkDialAddress runs on every connection, once DNS has resolved the host,
+// and refuses addresses inside the server's own network.
+func (r *remoteModels) checkDialAddress(_, address string, _ syscall.RawConn) error {
+	if r.allowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errModelHostBlocked
+	}
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return errModelHostBlocked
+	}
+	return nil
+}
+
+// fetch returns the model's file name and the path of a local copy of rawURL,
+// downloading it unless the cached copy is still current. Downloads larger
+// than MAX_UPLOAD_BYTES and HTML responses are rejected. The download is
+// canceled along with ctx.
+func (r *remoteModels) fetch(ctx context.Context, rawURL string) (name, modelPath string, err error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return "", "", newAPIError(400, codeInvalidInput, "model URL is invalid").withDetail("model", rawURL)
+	}
+	if err := r.checkURL(u); err != nil {
+		return "", "", errModelHostForbidden(rawURL)
+	}
+	name = path.Base(u.Path)
+	if apiErr := checkModelExtension(name); apiErr != nil {
+		return "", "", apiErr.withDetail("model", rawURL)
+	}
+
+	sum := sha256.Sum256([]byte(u.String()))
+	key := hex.EncodeToString(sum[:8])
+	modelPath = filepath.Join(r.dir, key+strings.ToLower(path.Ext(name)))
+	metaPath := filepath.Join(r.dir, key+".json")
+
+	lock, _ := r.locks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	cached := readRemoteModelMeta(metaPath, modelPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", "", newAPIError(400, codeInvalidInput, "model URL is invalid").withDetail("model", rawURL).wrap(err)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errModelHostBlocked) {
+			return "", "", errModelHostForbidden(rawURL).wrap(err)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", "", errCanceled(ctxErr)
+		}
+		return "", "", errModelFetch(rawURL, "remote model could not be fetched").wrap(err)
+	}
+	defer resp.Body.Close()
+
+	// Every failure reads the same, so the response reveals nothing about
+	// what answered at the URL.
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return name, modelPath, nil
+	case resp.StatusCode != http.StatusOK:
+		return "", "", errModelFetch(rawURL, "remote model could not be fetched").
+			wrap(fmt.Errorf("remote model server returned %s", resp.Status))
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/html" {
+		return "", "", newAPIError(415, codeUnsupportedModel, "remote model is an HTML page, not a model file").
+			withDetail("model", rawURL)
+	}
+	if resp.ContentLength > r.maxBytes {
+		return "", "", errUploadTooLarge(r.maxBytes).withDetail("model", rawURL)
+	}
+
+	if err := r.store(resp.Body, modelPath); err != nil {
+		return "", "", err
+	}
+	meta := remoteModelMeta{URL: u.String(), ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if data, err := json.Marshal(meta); err == nil {
+		// Without metadata the next request simply downloads the model again.
+		_ = os.WriteFile(metaPath, data, 0o644)
+	}
+	return name, modelPath, nil
+}
+
+// store copies body to dest through a temp file, so a failed or oversized
+// download never replaces a good cached copy.
+func (r *remoteModels) store(body io.Reader, dest string) error {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(r.dir, ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, io.LimitReader(body, r.maxBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errModelFetch("", "remote model download failed").wrap(err)
+	}
+	if n > r.maxBytes {
+		return errUploadTooLarge(r.maxBytes)
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// readRemoteModelMeta returns the validators of a cached copy, or nil when
+// there is no usable copy to revalidate.
+func readRemoteModelMeta(metaPath, modelPath string) *remoteModelMeta {
+	if _, err := os.Stat(modelPath); err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var meta remoteModelMeta
+	if err := json.Unmarshal(data, &meta); err != nil || (meta.ETag == "" && meta.LastModified == "") {
+		return nil
+	}
+	return &meta
+}
+
+func errModelHostForbidden(model string) *apiError {
+	return newAPIError(http.StatusForbidden, codeForbidden, errModelHostBlocked.Error()).withDetail("model", model)
+}
+
+func errModelFetch(model, message string) *apiError {
+	apiErr := newAPIError(http.StatusBadGateway, codeModelFetchFailed, message)
+	if model != "" {
+		apiErr.withDetail("model", model)
+	}
+	return apiErr
+}
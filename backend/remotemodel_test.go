@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// allowTestServerModels lets models be downloaded from httptest servers.
+func allowTestServerModels(t *testing.T) {
+	t.Setenv("MODEL_FETCH_HOSTS", "127.0.0.1")
+	t.Setenv("MODEL_FETCH_ALLOW_PRIVATE", "true")
+}
+
+func newRemoteModelRouter(t *testing.T, modelPath *string) http.Handler {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+	projectRoot := newTestProject(t, "mesh.obj", 4)
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			*modelPath = args[2]
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[0,5]}`), nil
+		},
+	})
+}
+
+func TestRemoteModelIsDownloadedAndRevalidated(t *testing.T) {
+	allowTestServerModels(t)
+	model := strings.Repeat("v 0 0 0\n", 8)
+	var downloads, revalidations atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, model)
+	}))
+	defer upstream.Close()
+
+	var modelPath string
+	router := newRemoteModelRouter(t, &modelPath)
+	for i, end := range []int{5, 6} {
+		body := fmt.Sprintf(`{"start":0,"end":%d,"model":%q}`, end, upstream.URL+"/graphs/city.obj?sig=abc")
+		if w := performRequest(router, http.MethodPost, "/compute", body); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected status 200, got %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	if downloads.Load() != 1 || revalidations.Load() != 1 {
+		t.Fatalf("expected 1 download and 1 revalidation, got %d and %d", downloads.Load(), revalidations.Load())
+	}
+	if filepath.Dir(modelPath) != filepath.Join(os.TempDir(), remoteModelDirName) || filepath.Ext(modelPath) != ".obj" {
+		t.Fatalf("expected the engine to load the cached copy, got %s", modelPath)
+	}
+	data, err := os.ReadFile(modelPath)
+	if err != nil || string(data) != model {
+		t.Fatalf("expected the cached copy to hold the downloaded model, got %q (%v)", data, err)
+	}
+}
+
+func TestRemoteModelRejectsOversizedResponse(t *testing.T) {
+	allowTestServerModels(t)
+	t.Setenv("MAX_UPLOAD_BYTES", "64")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stream without a Content-Length so the limit is enforced while reading.
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, strings.Repeat("v 0 0 0\n", 100))
+	}))
+	defer upstream.Close()
+
+	var modelPath string
+	router := newRemoteModelRouter(t, &modelPath)
+	w := performRequest(router, http.MethodPost, "/compute",
+		fmt.Sprintf(`{"start":0,"end":1,"model":%q}`, upstream.URL+"/big.obj"))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d: %s", w.Code, w.Body.String())
+	}
+	if resp := decodeJSONBody(t, w); resp["code"] != string(codePayloadTooLarge) {
+		t.Fatalf("expected code %s, got %v", codePayloadTooLarge, resp["code"])
+	}
+	if modelPath != "" {
+		t.Fatalf("expected the engine not to run")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(os.TempDir(), remoteModelDirName)); len(entries) != 0 {
+		t.Fatalf("expected no partial download to be kept, found %d entries", len(entries))
+	}
+}
+
+func TestRemoteModelHostsMustBeAllowed(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		fmt.Fprint(w, "v 0 0 0\n")
+	}))
+	defer upstream.Close()
+	body := fmt.Sprintf(`{"start":0,"end":1,"model":%q}`, upstream.URL+"/mesh.obj")
+
+	tests := []struct {
+		name, hosts, allowPrivate string
+	}{
+		{"remote models disabled", "", ""},
+		{"host not listed", "models.example.com", "true"},
+		// 127.0.0.1 is listed, but it is a loopback address.
+		{"private address", "127.0.0.1", "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MODEL_FETCH_HOSTS", tt.hosts)
+			t.Setenv("MODEL_FETCH_ALLOW_PRIVATE", tt.allowPrivate)
+			var modelPath string
+			w := performRequest(newRemoteModelRouter(t, &modelPath), http.MethodPost, "/compute", body)
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("expected status 403, got %d: %s", w.Code, w.Body.String())
+			}
+			if resp := decodeJSONBody(t, w); resp["code"] != string(codeForbidden) {
+				t.Fatalf("expected code %s, got %v", codeForbidden, resp["code"])
+			}
+		})
+	}
+	if hits.Load() != 0 {
+		t.Fatalf("expected no request to reach the server, got %d", hits.Load())
+	}
+}
+
+func TestRemoteModelRedirectsAreChecked(t *testing.T) {
+	allowTestServerModels(t)
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected the redirect not to be followed")
+	}))
+	defer internal.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// localhost resolves to the same machine but is not an allowed host.
+		http.Redirect(w, r, strings.Replace(internal.URL, "127.0.0.1", "localhost", 1)+"/mesh.obj", http.StatusFound)
+	}))
+	defer upstream.Close()
+
+	var modelPath string
+	w := performRequest(newRemoteModelRouter(t, &modelPath), http.MethodPost, "/compute",
+		fmt.Sprintf(`{"start":0,"end":1,"model":%q}`, upstream.URL+"/mesh.obj"))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRemoteModelFailuresDoNotRevealUpstreamStatus(t *testing.T) {
+	allowTestServerModels(t)
+	var modelPath string
+	router := newRemoteModelRouter(t, &modelPath)
+	var bodies []string
+	for _, status := range []int{http.StatusNotFound, http.StatusUnauthorized, http.StatusInternalServerError} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		w := performRequest(router, http.MethodPost, "/compute",
+			fmt.Sprintf(`{"start":0,"end":1,"model":%q}`, upstream.URL+"/mesh.obj"))
+		upstream.Close()
+		if w.Code != http.StatusBadGateway {
+			t.Fatalf("upstream %d: expected status 502, got %d: %s", status, w.Code, w.Body.String())
+		}
+		resp := decodeJSONBody(t, w)
+		details, _ := resp["details"].(map[string]any)
+		delete(details, "model")
+		encoded, _ := json.Marshal(resp)
+		bodies = append(bodies, string(encoded))
+	}
+	if bodies[0] != bodies[1] || bodies[1] != bodies[2] {
+		t.Fatalf("expected identical errors for every upstream status, got %v", bodies)
+	}
+}
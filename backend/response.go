@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// gzipMinBytes is the smallest payload worth compressing; below this the
+// gzip header and CPU cost outweigh the savings.
+const gzipMinBytes = 1024
+
+// payloadETag is a strong validator for an uncompressed engine payload.
+func payloadETag(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the payload's ETag and, for a GET whose If-None-Match
+// already names it, answers 304 with no body and reports true.
+func notModified(c *gin.Context, payload []byte) bool {
+	etag := payloadETag(payload)
+	c.Header("ETag", etag)
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches applies the weak comparison If-None-Match uses, so W/ prefixes
+// added by intermediaries still match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writePayload sends a JSON engine result; see writeBody.
+func writePayload(c *gin.Context, status int, payload []byte) {
+	writeBody(c, status, "application/json", payload)
+}
+
+// writeBody sends a result body, gzip-compressed when the client accepts it
+// and the body is large enough to benefit.
+func writeBody(c *gin.Context, status int, contentType string, body []byte) {
+	c.Writer.Header().Add("Vary", "Accept-Encoding")
+	if compressed, ok := gzipFor(c.GetHeader("Accept-Encoding"), body); ok {
+		c.Header("Content-Encoding", "gzip")
+		body = compressed
+	}
+	c.Data(status, contentType, body)
+}
+
+// gzipFor compresses body when an Accept-Encoding header allows gzip and the
+// body is large enough to benefit. ok is false when body should be sent as is.
+func gzipFor(acceptEncoding string, body []byte) (compressed []byte, ok bool) {
+	if len(body) < gzipMinBytes || !acceptsGzip(acceptEncoding) {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil || zw.Close() != nil {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip, honouring
+// an explicit q=0 refusal.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err != nil || weight > 0
+	}
+	return false
+}
+
+// cacheStatusHeader reports whether a result was computed for the request
+// (MISS), read from the result cache (HIT) or taken from an identical
+// request's engine run that was already in flight (HIT-SHARED).
+const cacheStatusHeader = "X-Cache"
+
+type cacheStatus string
+
+const (
+	cacheMiss      cacheStatus = "MISS"
+	cacheHit       cacheStatus = "HIT"
+	cacheHitShared cacheStatus = "HIT-SHARED"
+)
+
+// setCacheStatus sets cacheStatusHeader when status is known.
+func setCacheStatus(c *gin.Context, status cacheStatus) {
+	if status != "" {
+		c.Header(cacheStatusHeader, string(status))
+	}
+}
+
+// engineDurationHeader reports how long the engine subprocess ran for the
+// response, in whole milliseconds rounded up, excluding the result read.
+const engineDurationHeader = "X-Engine-Duration-Ms"
+
+// setEngineDuration sets engineDurationHeader unless the result came from the
+// cache, in which case no engine ran.
+func setEngineDuration(c *gin.Context, res engineResult) {
+	if res.cache == cacheHit || res.engineTime <= 0 {
+		return
+	}
+	c.Header(engineDurationHeader, strconv.FormatInt(durationMillis(res.engineTime), 10))
+}
+
+// durationMillis is d in whole milliseconds, rounded up.
+func durationMillis(d time.Duration) int64 {
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPayloadRouter(t *testing.T, payload []byte) http.Handler {
+	t.Helper()
+	return newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return payload, nil
+		},
+	})
+}
+
+func performGzipRequest(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLargePayloadIsGzippedWhenAccepted(t *testing.T) {
+	payload := []byte(`{"path":[` + strings.Repeat("1,", 2000) + `1]}`)
+	router := newPayloadRouter(t, payload)
+
+	w := performGzipRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("expected Content-Encoding gzip, got %q", got)
+	}
+
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("response is not gzip: %v", err)
+	}
+	decoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("failed to decompress: %v", err)
+	}
+	if !bytes.Equal(decoded, payload) {
+		t.Fatalf("decompressed payload does not match original")
+	}
+}
+
+func TestSmallPayloadAndHealthAreNotGzipped(t *testing.T) {
+	router := newPayloadRouter(t, []byte(`{"path":[],"curves":[]}`))
+
+	w := performGzipRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected small payload to be sent uncompressed, got %q", got)
+	}
+	if w.Body.String() != `{"path":[],"curves":[]}` {
+		t.Fatalf("unexpected body %s", w.Body.String())
+	}
+
+	w = performGzipRequest(router, http.MethodGet, "/health", "")
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected /health to be uncompressed, got %q", got)
+	}
+}
+
+func TestPayloadNotGzippedWithoutAcceptEncoding(t *testing.T) {
+	payload := []byte(`{"path":[` + strings.Repeat("1,", 2000) + `1]}`)
+	w := performRequest(newPayloadRouter(t, payload), http.MethodPost, "/compute",
+		`{"start":0,"end":1,"model":"mesh.obj"}`)
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("expected uncompressed response, got %q", got)
+	}
+	if !bytes.Equal(w.Body.Bytes(), payload) {
+		t.Fatalf("unexpected body")
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"deflate, GZIP":     true,
+		"gzip;q=0.5, br":    true,
+		"gzip;q=0":          false,
+		"br, gzip; q=0.000": false,
+		"identity":          false,
+	}
+	for header, expected := range cases {
+		if got := acceptsGzip(header); got != expected {
+			t.Fatalf("acceptsGzip(%q) = %v, expected %v", header, got, expected)
+		}
+	}
+}
+
+func TestComputeETagAndConditionalGet(t *testing.T) {
+	router := newPayloadRouter(t, []byte(`{"path":[0,3]}`))
+	url := "/compute?model=mesh.obj&start=0&end=3"
+
+	first := performRequest(router, http.MethodGet, url, "")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", first.Code)
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("expected a quoted ETag, got %q", etag)
+	}
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"stale", ` + etag} {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("If-None-Match %s: expected status 304, got %d", ifNoneMatch, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Fatalf("expected an empty 304 body, got %q", w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != `{"path":[0,3]}` {
+		t.Fatalf("expected a full response for a stale ETag, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestComputePostAlwaysReturnsBody(t *testing.T) {
+	router := newPayloadRouter(t, []byte(`{"path":[0,3]}`))
+	first := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":3,"model":"mesh.obj"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/compute", strings.NewReader(`{"start":0,"end":3,"model":"mesh.obj"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Fatalf("expected POST to return 200 with the same ETag, got %d", w.Code)
+	}
+}
+
+func TestEngineDurationHeader(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,1]}`), nil },
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	ms, err := strconv.Atoi(w.Header().Get(engineDurationHeader))
+	if err != nil || ms < 5 {
+		t.Fatalf("expected a duration of at least 5ms, got %q", w.Header().Get(engineDurationHeader))
+	}
+
+	cached := performRequest(router, http.MethodPost, "/compute", `{"start":0,"end":1,"model":"mesh.obj"}`)
+	if got := cached.Header().Get(engineDurationHeader); got != "" {
+		t.Fatalf("expected no engine duration for a cached result, got %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// projectRootMarkers identify the repository root: the bundled model
+// directory, or the backend module sitting next to it.
+var projectRootMarkers = []string{
+	filepath.Join("frontend", "public", "data"),
+	filepath.Join("backend", "go.mod"),
+}
+
+// defaultResolveProjectRoot returns PROJECT_ROOT when set. Otherwise it walks
+// upward from the working directory, then from the executable's directory,
+// until it finds a project root marker. The executable lookup covers a binary
+// started from elsewhere; the working directory covers `go run`, whose binary
+// lives in a temporary directory.
+func defaultResolveProjectRoot() (string, error) {
+	if root := os.Getenv("PROJECT_ROOT"); root != "" {
+		return filepath.Abs(root)
+	}
+
+	var starts []string
+	if wd, err := os.Getwd(); err == nil {
+		starts = append(starts, wd)
+	}
+	if exe, err := os.Executable(); err == nil {
+		starts = append(starts, filepath.Dir(exe))
+	}
+	for _, start := range starts {
+		if root, err := findProjectRoot(start); err == nil {
+			return root, nil
+		}
+	}
+	return "", errors.New("project root not found: set PROJECT_ROOT or run from inside the repository")
+}
+
+// findProjectRoot returns the nearest directory at or above start that
+// contains one of the project root markers.
+func findProjectRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, marker := range projectRootMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no project root above %s", start)
+		}
+		dir = parent
+	}
+}
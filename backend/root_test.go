@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveProjectRootUsesEnvOverride(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("PROJECT_ROOT", root)
+
+	got, err := defaultResolveProjectRoot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != root {
+		t.Fatalf("expected %s, got %s", root, got)
+	}
+}
+
+func TestFindProjectRootWalksUpToMarker(t *testing.T) {
+	for _, marker := range projectRootMarkers {
+		t.Run(marker, func(t *testing.T) {
+			root := t.TempDir()
+			// Stat is all the walk needs, so a directory stands in for go.mod.
+			if err := os.MkdirAll(filepath.Join(root, marker), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			nested := filepath.Join(root, "backend", "cmd", "tool")
+			if err := os.MkdirAll(nested, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := findProjectRoot(nested)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != root {
+				t.Fatalf("expected %s, got %s", root, got)
+			}
+		})
+	}
+}
+
+func TestFindProjectRootFailsWithoutMarker(t *testing.T) {
+	if _, err := findProjectRoot(t.TempDir()); err == nil {
+		t.Fatalf("expected an error when no marker exists")
+	}
+}
+
+func TestResolveProjectRootFindsRepositoryFromBackendDir(t *testing.T) {
+	t.Setenv("PROJECT_ROOT", "")
+
+	got, err := defaultResolveProjectRoot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := filepath.Abs("..")
+	if got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
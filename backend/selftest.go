@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// selfTestModel is a unit square split along its 0-2 diagonal. The shortest
+// path from vertex 0 to vertex 2 is that diagonal (length √2), not the two
+// sides through vertex 1 or 3 (length 2).
+const selfTestModel = `v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+f 1 2 3
+f 1 3 4
+`
+
+const selfTestStart, selfTestEnd = 0, 2
+
+var selfTestPath = []int{selfTestStart, selfTestEnd}
+
+// handleSelfTest runs the engine on selfTestModel and checks its answer. It
+// bypasses the result cache so every call exercises the real binary, which
+// catches engine and model-format mismatches that /health cannot see.
+func (s *server) handleSelfTest(c *gin.Context) {
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+	dir, err := os.MkdirTemp(s.engineOutputBase(), engineOutputPrefix+"selftest-*")
+	if err != nil {
+		respondError(c, toAPIError(err))
+		return
+	}
+	defer os.RemoveAll(dir)
+	modelPath := filepath.Join(dir, "selftest.obj")
+	if err := os.WriteFile(modelPath, []byte(selfTestModel), 0o644); err != nil {
+		respondError(c, toAPIError(err))
+		return
+	}
+
+	res, err := s.execute(c.Request.Context(), engineJob{
+		requestID:      requestIDFrom(c),
+		mode:           "dijkstra",
+		start:          selfTestStart,
+		end:            selfTestEnd,
+		projectRoot:    projectRoot,
+		model:          "selftest.obj",
+		modelPath:      modelPath,
+		args:           []string{fmt.Sprint(selfTestStart), fmt.Sprint(selfTestEnd), modelPath},
+		resultFileName: resultFileForMode(""),
+		resultKeys:     pathResultKeys,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var result struct {
+		Path []int `json:"path"`
+	}
+	if err := json.Unmarshal(res.payload, &result); err != nil || !slices.Equal(result.Path, selfTestPath) {
+		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "expected": selfTestPath, "got": result.Path})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
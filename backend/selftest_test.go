@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSelfTestComparesEngineAnswer(t *testing.T) {
+	cases := []struct {
+		name   string
+		result string
+		status int
+		body   string
+	}{
+		{name: "match", result: `{"path":[0,2],"totalDistance":1.41421}`, status: http.StatusOK, body: `{"ok":true}`},
+		{name: "mismatch", result: `{"path":[0,1,2]}`, status: http.StatusInternalServerError,
+			body: `{"expected":[0,2],"got":[0,1,2],"ok":false}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotArgs []string
+			var fixture string
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return t.TempDir(), nil },
+				runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+					gotArgs, _ = splitOutputDir(args)
+					data, err := os.ReadFile(gotArgs[2])
+					fixture = string(data)
+					return nil, err
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(tc.result), nil },
+			})
+
+			// Run twice: the self-test never answers from the result cache.
+			for i := 0; i < 2; i++ {
+				gotArgs = nil
+				w := performRequest(router, http.MethodGet, "/selftest", "")
+				if w.Code != tc.status || w.Body.String() != tc.body {
+					t.Fatalf("expected %d %s, got %d %s", tc.status, tc.body, w.Code, w.Body.String())
+				}
+				if len(gotArgs) != 3 || gotArgs[0] != "0" || gotArgs[1] != "2" {
+					t.Fatalf("expected the engine to run on the fixture, got %v", gotArgs)
+				}
+			}
+			if fixture != selfTestModel {
+				t.Fatalf("expected the engine to read the built-in fixture, got %q", fixture)
+			}
+		})
+	}
+}
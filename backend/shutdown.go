@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultShutdownGrace = 20 * time.Second
+
+// engineTracker counts running engine subprocesses so shutdown can wait for
+// them, and owns the context they run under so stragglers can be killed.
+type engineTracker struct {
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newEngineTracker() *engineTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &engineTracker{ctx: ctx, cancel: cancel}
+}
+
+// start registers a running engine; call the returned func when it exits.
+func (t *engineTracker) start() func() {
+	t.wg.Add(1)
+	return t.wg.Done
+}
+
+// drain waits for running engines until ctx expires, then kills whatever is
+// left and waits for those processes to be reaped.
+func (t *engineTracker) drain(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("shutdown grace period expired, killing running engines")
+		t.cancel()
+		<-done
+	}
+}
+
+// handleShutdown starts a graceful shutdown, for deployments without a
+// process manager to send SIGTERM. It is disabled unless API_KEY is set, and
+// answers 202 before the server stops accepting requests.
+func (s *server) handleShutdown(c *gin.Context) {
+	if s.cfg.apiKey == "" {
+		respondError(c, newAPIError(http.StatusForbidden, codeForbidden, "shutdown requires API_KEY to be set"))
+		return
+	}
+	log.Printf("shutdown requested by %s", c.ClientIP())
+	c.JSON(http.StatusAccepted, gin.H{"status": "shutting down"})
+	c.Writer.Flush()
+	// serve waits for this handler to return before closing the connection.
+	s.stop()
+}
+
+// serve runs the HTTP server on ln until ctx is cancelled, then shuts down
+// gracefully: in-flight requests and engine runs get up to grace to finish.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, engines *engineTracker, grace time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("shutting down, waiting up to %s for in-flight requests", grace)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	engines.drain(shutdownCtx)
+	if serveErr := <-errCh; serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeShutdownWaitsForInFlightEngine(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engineStarted := make(chan struct{})
+	s := newServer(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			close(engineStarted)
+			time.Sleep(200 * time.Millisecond)
+			return []byte(""), nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[],"curves":[]}`), nil
+		},
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, &http.Server{Handler: s.router()}, ln, s.engines, 5*time.Second)
+	}()
+
+	type response struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		resp, err := http.Post("http://"+ln.Addr().String()+"/compute", "application/json",
+			strings.NewReader(`{"start":0,"end":1,"model":"mesh.obj"}`))
+		if err != nil {
+			responses <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- response{status: resp.StatusCode, body: string(body)}
+	}()
+
+	<-engineStarted
+	cancel()
+
+	got := <-responses
+	if got.err != nil {
+		t.Fatalf("in-flight request failed during shutdown: %v", got.err)
+	}
+	if got.status != http.StatusOK || got.body != `{"path":[],"curves":[]}` {
+		t.Fatalf("expected in-flight request to complete, got %d %s", got.status, got.body)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestEngineTrackerDrainKillsAfterGrace(t *testing.T) {
+	tracker := newEngineTracker()
+	done := tracker.start()
+	go func() {
+		<-tracker.ctx.Done()
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	tracker.drain(ctx)
+
+	if tracker.ctx.Err() == nil {
+		t.Fatalf("expected running engines to be cancelled after the grace period")
+	}
+}
+
+func TestShutdownEndpointRequiresAPIKeyAndStopsServer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("API_KEY", "s3cret")
+	s := newServer(appDeps{})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(s.stopping, &http.Server{Handler: s.router()}, ln, s.engines, 5*time.Second)
+	}()
+
+	post := func(key string) int {
+		req, _ := http.NewRequest(http.MethodPost, "http://"+ln.Addr().String()+"/shutdown", nil)
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("shutdown request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, key := range []string{"", "guess"} {
+		if code := post(key); code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for key %q, got %d", key, code)
+		}
+	}
+	if s.stopping.Err() != nil {
+		t.Fatalf("expected an unauthenticated call not to start shutdown")
+	}
+
+	if code := post("s3cret"); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the server to shut down")
+	}
+}
+
+func TestShutdownEndpointIsDisabledWithoutAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("API_KEY", "")
+	s := newServer(appDeps{})
+	w := performRequest(s.router(), http.MethodPost, "/shutdown", "")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+	if s.stopping.Err() != nil {
+		t.Fatalf("expected shutdown not to start")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// modeRequest is a computeRequest that also names the engine mode, as used by
+// the endpoints that serve every mode from one route.
+type modeRequest struct {
+	computeRequest
+	Mode string `json:"mode"`
+}
+
+// bindModeJob decodes a modeRequest and builds its engine job. On failure the
+// error has already been written and ok is false.
+func (s *server) bindModeJob(c *gin.Context) (job engineJob, ok bool) {
+	var req modeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, errRequestBody(err))
+		return engineJob{}, false
+	}
+	c.Set(ctxComputeRequest, req.computeRequest)
+
+	route, ok := batchModes[strings.ToLower(strings.TrimSpace(req.Mode))]
+	if !ok {
+		respondError(c, newAPIError(400, codeInvalidInput,
+			fmt.Sprintf("unsupported mode %q", req.Mode)))
+		return engineJob{}, false
+	}
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return engineJob{}, false
+	}
+	job, err = s.buildJob(c.Request.Context(), requestIDFrom(c), projectRoot, req.computeRequest, route)
+	if err != nil {
+		respondError(c, err)
+		return engineJob{}, false
+	}
+	return job, true
+}
+
+func defaultStreamEngine(ctx context.Context, workDir, enginePath string, onLine func(string), args ...string) ([]byte, error) {
+	cmd := execCommand(ctx, enginePath, args...)
+	cmd.Dir = workDir
+	configureProcessGroup(cmd)
+	cmd.WaitDelay = time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	nice := engineNiceFrom(ctx)
+	niced := niceCommand(cmd, nice)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if !niced {
+		lowerPriority(cmd, nice)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	err = cmd.Wait()
+	return stderr.Bytes(), err
+}
+
+// progressLineHandler turns "PROGRESS <n>" engine output into onProgress
+// calls; every other line is ignored.
+func progressLineHandler(onProgress func(int)) func(string) {
+	return func(line string) {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), "PROGRESS ")
+		if !ok {
+			return
+		}
+		if percent, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil {
+			onProgress(percent)
+		}
+	}
+}
+
+// writeSSE writes one Server-Sent Event. Data must not contain newlines.
+func writeSSE(c *gin.Context, event, data string) {
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, data)
+	c.Writer.Flush()
+}
+
+// handleStream runs a computation while forwarding engine progress as
+// "progress" events, then sends the payload as a final "result" event (or an
+// "error" event). A client disconnect cancels the engine.
+func (s *server) handleStream(c *gin.Context) {
+	job, ok := s.bindModeJob(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	job.onProgress = func(percent int) {
+		writeSSE(c, "progress", strconv.Itoa(percent))
+	}
+	res, err := s.runEngine(c.Request.Context(), job)
+	if err != nil {
+		apiErr := toAPIError(err)
+		body, _ := json.Marshal(errorResponse{Code: apiErr.code, Message: apiErr.message, Details: apiErr.details})
+		writeSSE(c, "error", string(body))
+		return
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, res.payload); err != nil {
+		body, _ := json.Marshal(errorResponse{Code: codeResultUnreadable, Message: "engine produced invalid JSON"})
+		writeSSE(c, "error", string(body))
+		return
+	}
+	writeSSE(c, "result", compact.String())
+}
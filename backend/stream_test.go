@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamForwardsProgressAndResult(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	var gotArgs []string
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			t.Fatalf("streaming requests should use streamEngine")
+			return nil, nil
+		},
+		streamEngine: func(_ context.Context, projectRoot, enginePath string, onLine func(string), args ...string) ([]byte, error) {
+			gotArgs, _ = splitOutputDir(args)
+			for _, line := range []string{"--- Geodesic Lab ---", "PROGRESS 10", "PROGRESS  55 ", "PROGRESS soon", "PROGRESS 100"} {
+				onLine(line)
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte("{\n  \"curves\": [0, 3]\n}\n"), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_stream",
+		`{"start":0,"end":3,"model":"mesh.obj","mode":"heat"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	expected := "event: progress\ndata: 10\n\n" +
+		"event: progress\ndata: 55\n\n" +
+		"event: progress\ndata: 100\n\n" +
+		"event: result\ndata: {\"curves\":[0,3]}\n\n"
+	if w.Body.String() != expected {
+		t.Fatalf("unexpected SSE stream:\n%q\nwant:\n%q", w.Body.String(), expected)
+	}
+	if len(gotArgs) != 4 || gotArgs[3] != "heat" {
+		t.Fatalf("expected heat mode to be forwarded, got %v", gotArgs)
+	}
+}
+
+func TestStreamReportsEngineFailureAsErrorEvent(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		streamEngine: func(_ context.Context, projectRoot, enginePath string, onLine func(string), args ...string) ([]byte, error) {
+			onLine("PROGRESS 20")
+			return []byte("solver diverged"), errors.New("exit status 2")
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/compute_stream", `{"start":0,"end":3,"model":"mesh.obj"}`)
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "event: progress\ndata: 20\n\n") {
+		t.Fatalf("expected progress before the failure, got %q", body)
+	}
+	if !strings.Contains(body, "event: error\ndata: {\"code\":\"ENGINE_FAILED\",\"message\":\"solver diverged\"") {
+		t.Fatalf("expected error event, got %q", body)
+	}
+}
+
+func TestStreamCancelsEngineWhenClientDisconnects(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 16)
+	cancelled := make(chan struct{})
+	started := make(chan struct{})
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		streamEngine: func(ctx context.Context, projectRoot, enginePath string, onLine func(string), args ...string) ([]byte, error) {
+			close(started)
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/compute_stream",
+		strings.NewReader(`{"start":0,"end":3,"model":"mesh.obj"}`)).WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected engine context to be cancelled on disconnect")
+	}
+	<-done
+}
+
+func TestStreamRejectsUnknownMode(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return newTestProject(t, "mesh.obj", 16), nil
+		},
+	})
+	w := performRequest(router, http.MethodPost, "/compute_stream",
+		`{"start":0,"end":3,"model":"mesh.obj","mode":"warp"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+// pathSummary is what ?summary=true returns in place of a path result, for
+// clients that only need the length and not the vertex list. Distance is the
+// engine's totalDistance, passed through as written and omitted when the
+// target is unreachable, and Found is its reachable flag.
+type pathSummary struct {
+	Distance  json.RawMessage `json:"distance,omitempty"`
+	NodeCount int             `json:"nodeCount"`
+	Found     bool            `json:"found"`
+}
+
+// summarizePath reduces a path result to its pathSummary. Found falls back
+// to whether the path is non-empty for results without a reachable flag.
+func summarizePath(payload []byte) ([]byte, error) {
+	var result struct {
+		Path          []json.RawMessage `json:"path"`
+		TotalDistance json.RawMessage   `json:"totalDistance"`
+		Reachable     *bool             `json:"reachable"`
+	}
+	if err := json.Unmarshal(payload, &result); err != nil {
+		return nil, toAPIError(err)
+	}
+	summary := pathSummary{
+		NodeCount: len(result.Path),
+		Found:     len(result.Path) > 0,
+	}
+	if result.Reachable != nil {
+		summary.Found = *result.Reachable
+	}
+	if summary.Found && string(result.TotalDistance) != "null" {
+		summary.Distance = result.TotalDistance
+	}
+	return json.Marshal(summary)
+}
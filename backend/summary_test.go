@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSummaryReplacesThePath(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			// The shape the engine writes; see frontend/public/result.json.
+			return []byte(`{"inputFileName":"mesh.obj","reachable":true,"totalDistance":2.75,"path":[1,3,5,4],"elapsedMs":0.01,"allDistances":[0,1,2,3]}`), nil
+		},
+	})
+
+	cases := []struct{ name, method, path, body, want string }{
+		{name: "full", method: http.MethodPost, path: "/compute", body: `{"start":1,"end":4,"model":"mesh.obj"}`, want: `{"inputFileName":"mesh.obj","reachable":true,"totalDistance":2.75,"path":[1,3,5,4],"elapsedMs":0.01,"allDistances":[0,1,2,3]}`},
+		{name: "summary", method: http.MethodPost, path: "/compute?summary=true", body: `{"start":1,"end":4,"model":"mesh.obj"}`, want: `{"distance":2.75,"nodeCount":4,"found":true}`},
+		{name: "same node", method: http.MethodPost, path: "/compute?summary=true", body: `{"start":2,"end":2,"model":"mesh.obj"}`, want: `{"distance":0,"nodeCount":1,"found":true}`},
+		{name: "get", method: http.MethodGet, path: "/compute_bfs_path?start=1&end=4&model=mesh.obj&summary=1", want: `{"distance":2.75,"nodeCount":4,"found":true}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performRequest(router, tc.method, tc.path, tc.body)
+			if w.Code != http.StatusOK || w.Body.String() != tc.want {
+				t.Fatalf("expected 200 %s, got %d %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := performRequest(router, http.MethodPost, "/heat?summary=true", `{"start":1,"end":4,"model":"mesh.obj"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected summary to be rejected for heat, got %d", w.Code)
+	}
+}
+
+func TestSummaryOfUnreachableTarget(t *testing.T) {
+	got, err := summarizePath([]byte(`{"inputFileName":"mesh.obj","reachable":false,"totalDistance":null,"path":[],"elapsedMs":0.01,"allDistances":[0,null]}`))
+	if err != nil || string(got) != `{"nodeCount":0,"found":false}` {
+		t.Fatalf("unexpected summary %s (%v)", got, err)
+	}
+}
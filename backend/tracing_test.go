@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestComputeRequestCreatesEngineSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"path":[1,2]}`), nil
+		},
+		tracerProvider: tp,
+	})
+
+	if w := performRequest(router, http.MethodPost, "/compute", `{"start":1,"end":2,"model":"mesh.obj"}`); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"POST /compute", "validate", "runEngine", "engine.run", "engine.read_result"} {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("expected a %q span, got %v", name, spanNames(recorder.Ended()))
+		}
+	}
+
+	root := spans["POST /compute"].SpanContext().TraceID()
+	for name, span := range spans {
+		if span.SpanContext().TraceID() != root {
+			t.Fatalf("expected %q to share the request trace", name)
+		}
+	}
+
+	want := map[attribute.Key]attribute.Value{
+		"geodesic.model": attribute.StringValue("mesh.obj"),
+		"geodesic.mode":  attribute.StringValue("dijkstra"),
+		"geodesic.start": attribute.IntValue(1),
+		"geodesic.end":   attribute.IntValue(2),
+	}
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans["engine.run"].Attributes() {
+		got[kv.Key] = kv.Value
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("expected engine.run attribute %s=%v, got %v", key, value.Emit(), got[key].Emit())
+		}
+	}
+}
+
+func spanNames(spans []sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	return names
+}
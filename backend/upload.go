@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxUploadBytes = 20 << 20
+
+// uploadResponse describes a model stored by POST /models.
+type uploadResponse struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"sizeBytes"`
+}
+
+// handleUploadModel stores a multipart "file" in the data directory. Existing
+// models are only replaced when the "overwrite" form field is "true".
+func (s *server) handleUploadModel(c *gin.Context) {
+	maxBytes := s.cfg.maxUploadBytes
+	// Leave headroom for the multipart envelope around the file itself.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+1<<20)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, errUploadTooLarge(maxBytes))
+			return
+		}
+		respondError(c, newAPIError(400, codeInvalidInput, "multipart field \"file\" is required"))
+		return
+	}
+	if header.Size > maxBytes {
+		respondError(c, errUploadTooLarge(maxBytes))
+		return
+	}
+
+	name := filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		respondError(c, newAPIError(400, codeInvalidInput, "invalid model file name").
+			withDetail("name", header.Filename))
+		return
+	}
+	if apiErr := checkModelExtension(name); apiErr != nil {
+		respondError(c, apiErr.withDetail("name", name))
+		return
+	}
+
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+	dir := s.modelDir(projectRoot)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		respondError(c, newAPIError(500, codeInternal, "failed to create model directory").wrap(err))
+		return
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		respondError(c, newAPIError(400, codeInvalidInput, "failed to read uploaded file").wrap(err))
+		return
+	}
+	defer src.Close()
+
+	written, err := storeModelAtomically(src, filepath.Join(dir, name), c.PostForm("overwrite") == "true")
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			respondError(c, newAPIError(409, codeModelExists,
+				"model already exists, set overwrite=true to replace it").withDetail("name", name))
+			return
+		}
+		respondError(c, newAPIError(500, codeInternal, "failed to store model").wrap(err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, uploadResponse{Name: name, SizeBytes: written})
+}
+
+// storeModelAtomically writes src to a hidden temp file next to dst and only
+// then moves it into place, so a compute reading dst sees either the old model
+// or the new one and never a partial write. Without overwrite it links
+// instead of renaming, which fails with fs.ErrExist if dst already exists.
+func storeModelAtomically(src io.Reader, dst string, overwrite bool) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+	written, err := io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return 0, err
+	}
+	if overwrite {
+		return written, os.Rename(tmp.Name(), dst)
+	}
+	return written, os.Link(tmp.Name(), dst)
+}
+
+// handleDeleteModel removes a model from the data directory. Only the final
+// path element of the name is used, so nothing outside the directory can be
+// deleted.
+func (s *server) handleDeleteModel(c *gin.Context) {
+	name := filepath.Base(strings.ReplaceAll(c.Param("name"), "\\", "/"))
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		respondError(c, newAPIError(400, codeInvalidInput, "invalid model file name").
+			withDetail("name", c.Param("name")))
+		return
+	}
+	projectRoot, err := s.deps.resolveProjectRoot()
+	if err != nil {
+		respondError(c, errProjectRoot(err))
+		return
+	}
+	path := filepath.Join(s.modelDir(projectRoot), name)
+	if _, err := statModel(name, path); err != nil {
+		respondError(c, err)
+		return
+	}
+	// Checked before removal, which would hide the gzip magic bytes.
+	gzipped := isGzipModel(name, path)
+	if err := os.Remove(path); err != nil {
+		respondError(c, newAPIError(500, codeInternal, "failed to delete model").wrap(err))
+		return
+	}
+	if gzipped {
+		path = decompressedModelPath(path)
+		os.Remove(path)
+	}
+	if isCSVModel(name) {
+		os.Remove(convertedModelPath(path))
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func errUploadTooLarge(maxBytes int64) *apiError {
+	return newAPIError(413, codePayloadTooLarge,
+		fmt.Sprintf("model exceeds the %d byte upload limit", maxBytes)).
+		withDetail("limitBytes", maxBytes)
+}
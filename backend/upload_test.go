@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func performUpload(t *testing.T, router http.Handler, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatalf("failed to write field: %v", err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	fw.Write([]byte(content))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/models", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newUploadRouter(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	projectRoot := t.TempDir()
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+	})
+	return router, filepath.Join(projectRoot, "frontend", "public", "data")
+}
+
+func TestUploadModelStoresFile(t *testing.T) {
+	router, dataDir := newUploadRouter(t)
+
+	w := performUpload(t, router, "tri.obj", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var got uploadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Name != "tri.obj" || got.SizeBytes != 32 {
+		t.Fatalf("unexpected upload response %+v", got)
+	}
+	stored, err := os.ReadFile(filepath.Join(dataDir, "tri.obj"))
+	if err != nil || !strings.HasPrefix(string(stored), "v 0 0 0") {
+		t.Fatalf("expected model to be stored, got %q (%v)", stored, err)
+	}
+}
+
+func TestUploadModelRejectsOversizedFile(t *testing.T) {
+	t.Setenv("MAX_UPLOAD_BYTES", "16")
+	router, dataDir := newUploadRouter(t)
+
+	w := performUpload(t, router, "big.obj", strings.Repeat("v 0 0 0\n", 10), nil)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", w.Code)
+	}
+	if code := decodeJSONBody(t, w)["code"]; code != string(codePayloadTooLarge) {
+		t.Fatalf("expected PAYLOAD_TOO_LARGE, got %#v", code)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "big.obj")); err == nil {
+		t.Fatalf("oversized upload should not be stored")
+	}
+}
+
+func TestUploadModelSanitizesTraversalFilename(t *testing.T) {
+	router, dataDir := newUploadRouter(t)
+
+	w := performUpload(t, router, "../../../etc/evil.obj", "v 0 0 0\n", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "evil.obj")); err != nil {
+		t.Fatalf("expected sanitized file inside data dir: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "..", "..", "..", "etc", "evil.obj")); err == nil {
+		t.Fatalf("upload escaped the data directory")
+	}
+}
+
+func TestUploadModelRequiresOverwriteFlag(t *testing.T) {
+	router, dataDir := newUploadRouter(t)
+
+	if w := performUpload(t, router, "mesh.obj", "v 0 0 0\n", nil); w.Code != http.StatusCreated {
+		t.Fatalf("expected first upload to succeed, got %d", w.Code)
+	}
+	w := performUpload(t, router, "mesh.obj", "v 1 1 1\n", nil)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+	w = performUpload(t, router, "mesh.obj", "v 2 2 2\n", map[string]string{"overwrite": "true"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected overwrite to succeed, got %d", w.Code)
+	}
+	stored, _ := os.ReadFile(filepath.Join(dataDir, "mesh.obj"))
+	if string(stored) != "v 2 2 2\n" {
+		t.Fatalf("expected overwritten content, got %q", stored)
+	}
+}
+
+func TestUploadModelRejectsUnsupportedExtension(t *testing.T) {
+	router, _ := newUploadRouter(t)
+
+	w := performUpload(t, router, "notes.txt", "hello", nil)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected status 415, got %d", w.Code)
+	}
+	if msg, _ := decodeJSONBody(t, w)["message"].(string); !strings.Contains(msg, ".obj") {
+		t.Fatalf("expected accepted types in message, got %q", msg)
+	}
+}
+
+func TestDeleteModelRemovesFile(t *testing.T) {
+	router, dataDir := newUploadRouter(t)
+	if w := performUpload(t, router, "tri.obj", "v 0 0 0\n", nil); w.Code != http.StatusCreated {
+		t.Fatalf("expected upload to succeed, got %d", w.Code)
+	}
+
+	w := performRequest(router, http.MethodDelete, "/models/tri.obj", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d (%s)", w.Code, w.Body.String())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "tri.obj")); !os.IsNotExist(err) {
+		t.Fatalf("expected the model to be removed, stat err = %v", err)
+	}
+}
+
+func TestDeleteModelMissingFile(t *testing.T) {
+	router, _ := newUploadRouter(t)
+	w := performRequest(router, http.MethodDelete, "/models/ghost.obj", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+	if resp := decodeJSONBody(t, w); resp["code"] != string(codeModelNotFound) {
+		t.Fatalf("expected code %s, got %v", codeModelNotFound, resp["code"])
+	}
+}
+
+func TestDeleteModelCannotEscapeDataDir(t *testing.T) {
+	router, dataDir := newUploadRouter(t)
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		t.Fatalf("failed to create data dir: %v", err)
+	}
+	outside := filepath.Join(filepath.Dir(dataDir), "secret.obj")
+	if err := os.WriteFile(outside, []byte("v 0 0 0\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	for _, name := range []string{"..%5Csecret.obj", "..%2Fsecret.obj", ".."} {
+		w := performRequest(router, http.MethodDelete, "/models/"+name, "")
+		if w.Code != http.StatusNotFound && w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 404 or 400, got %d", name, w.Code)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("expected the file outside the data dir to survive: %v", err)
+	}
+}
+
+func TestComputeNeverSeesPartialUpload(t *testing.T) {
+	t.Setenv("RATE_LIMIT", "0")
+	const vertices = 100000
+	var obj strings.Builder
+	for i := 0; i < vertices; i++ {
+		fmt.Fprintf(&obj, "v %d 0 0\n", i)
+	}
+	projectRoot := newTestProject(t, "big.obj", vertices)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) { return []byte(`{"path":[0,1]}`), nil },
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			if w := performUpload(t, router, "big.obj", obj.String(), map[string]string{"overwrite": "true"}); w.Code != http.StatusCreated {
+				t.Errorf("upload %d: expected status 201, got %d", i, w.Code)
+			}
+		}
+	}()
+	// Distinct endpoints so no compute is answered from the cache.
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		body := fmt.Sprintf(`{"start":%d,"end":%d,"model":"big.obj"}`, i%vertices, vertices-1)
+		if w := performRequest(router, http.MethodPost, "/compute", body); w.Code != http.StatusOK {
+			t.Errorf("compute %d during uploads: expected status 200, got %d: %s", i, w.Code, w.Body.String())
+			<-done
+			return
+		}
+	}
+}
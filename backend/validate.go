@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type validateResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// handleValidate runs every check a computation would (mode, model name and
+// extension, model existence, index bounds) without starting the engine, so
+// clients can vet a selection before a long run.
+func (s *server) handleValidate(c *gin.Context) {
+	job, ok := s.bindModeJob(c)
+	if !ok {
+		return
+	}
+	if _, err := statModel(job.model, job.modelPath); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, validateResponse{Valid: true})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestValidateChecksInputsWithoutRunningEngine(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) {
+			return projectRoot, nil
+		},
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			t.Fatalf("/validate must not run the engine")
+			return nil, nil
+		},
+	})
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		code   errorCode
+	}{
+		{name: "valid", body: `{"start":0,"end":9,"model":"mesh.obj"}`, status: http.StatusOK},
+		{name: "valid astar", body: `{"start":0,"end":9,"model":"mesh.obj","mode":"astar","heuristic":"manhattan"}`, status: http.StatusOK},
+		{name: "malformed json", body: `{"start":`, status: http.StatusBadRequest, code: codeInvalidInput},
+		{name: "unknown mode", body: `{"start":0,"end":9,"model":"mesh.obj","mode":"warp"}`, status: http.StatusBadRequest, code: codeInvalidInput},
+		{name: "missing end", body: `{"start":0,"model":"mesh.obj"}`, status: http.StatusBadRequest, code: codeInvalidInput},
+		{name: "unknown heuristic", body: `{"start":0,"end":9,"model":"mesh.obj","mode":"astar","heuristic":"chebyshev"}`, status: http.StatusBadRequest, code: codeInvalidInput},
+		{name: "unsupported extension", body: `{"start":0,"end":9,"model":"mesh.txt"}`, status: http.StatusUnsupportedMediaType, code: codeUnsupportedModel},
+		{name: "missing model", body: `{"start":0,"end":9,"model":"ghost.obj"}`, status: http.StatusNotFound, code: codeModelNotFound},
+		{name: "index out of range", body: `{"start":0,"end":10,"model":"mesh.obj"}`, status: http.StatusUnprocessableEntity, code: codeInvalidInput},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := performRequest(router, http.MethodPost, "/validate", tc.body)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d (%s)", tc.status, w.Code, w.Body.String())
+			}
+			body := decodeJSONBody(t, w)
+			if tc.status == http.StatusOK {
+				if body["valid"] != true {
+					t.Fatalf("expected {\"valid\":true}, got %v", body)
+				}
+				return
+			}
+			if body["code"] != string(tc.code) {
+				t.Fatalf("expected code %s, got %v", tc.code, body["code"])
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type warmupResponse struct {
+	Warmed bool   `json:"warmed"`
+	Key    string `json:"key"`
+	// Reason says why nothing was cached when warmed is false.
+	Reason string `json:"reason,omitempty"`
+}
+
+// handleWarmup runs a computation so that its result is cached, answering
+// with the cache key instead of the payload. Operators use it to make the
+// first real request for a demo model fast. warmed is only true once the
+// result is in the cache; with CACHE_SIZE=0 or a same-node path, which is
+// answered without the engine, nothing runs and warmed is false.
+func (s *server) handleWarmup(c *gin.Context) {
+	job, ok := s.bindModeJob(c)
+	if !ok {
+		return
+	}
+	key, err := jobCacheKey(job)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	switch {
+	case !s.cache.enabled():
+		c.JSON(http.StatusOK, warmupResponse{Key: key, Reason: "result cache is disabled"})
+		return
+	case job.sameNodePath && job.start == job.end:
+		c.JSON(http.StatusOK, warmupResponse{Key: key, Reason: "same-node paths are not cached"})
+		return
+	}
+	if _, err := s.runEngine(c.Request.Context(), job); err != nil {
+		respondError(c, err)
+		return
+	}
+	if _, ok := s.cache.lookup(key); !ok {
+		c.JSON(http.StatusOK, warmupResponse{Key: key, Reason: "result was not kept in the cache"})
+		return
+	}
+	c.JSON(http.StatusOK, warmupResponse{Warmed: true, Key: key})
+}
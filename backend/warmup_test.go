@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestWarmupCachesResultForLaterRequests(t *testing.T) {
+	projectRoot := newTestProject(t, "mesh.obj", 10)
+	engineRuns := 0
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+		runEngine: func(_ context.Context, projectRoot, enginePath string, args ...string) ([]byte, error) {
+			engineRuns++
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			return []byte(`{"curves":[[0,1,2]]}`), nil
+		},
+	})
+
+	w := performRequest(router, http.MethodPost, "/warmup", `{"model":"mesh.obj","start":1,"end":2,"mode":"heat"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var warm warmupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &warm); err != nil {
+		t.Fatalf("failed to decode warmup response: %v", err)
+	}
+	if !warm.Warmed || len(warm.Key) != 64 {
+		t.Fatalf("expected warmed with a cache key, got %s", w.Body.String())
+	}
+	if engineRuns != 1 {
+		t.Fatalf("expected warmup to run the engine once, got %d", engineRuns)
+	}
+
+	w = performRequest(router, http.MethodPost, "/heat", `{"model":"mesh.obj","start":1,"end":2}`)
+	if w.Code != http.StatusOK || w.Body.String() != `{"curves":[[0,1,2]]}` {
+		t.Fatalf("expected the warmed result, got %d %s", w.Code, w.Body.String())
+	}
+	if engineRuns != 1 {
+		t.Fatalf("expected the request after warmup to be served from cache, got %d engine runs", engineRuns)
+	}
+}
+
+func TestWarmupRejectsUnknownMode(t *testing.T) {
+	router := newTestRouter(appDeps{
+		resolveProjectRoot: func() (string, error) { return newTestProject(t, "mesh.obj", 10), nil },
+	})
+	w := performRequest(router, http.MethodPost, "/warmup", `{"model":"mesh.obj","start":1,"end":2,"mode":"warp"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestWarmupReportsWhenNothingIsCached(t *testing.T) {
+	cases := []struct {
+		name, cacheSize, body, reason string
+	}{
+		{name: "cache disabled", cacheSize: "0", body: `{"model":"mesh.obj","start":1,"end":2,"mode":"heat"}`,
+			reason: "result cache is disabled"},
+		{name: "same node", body: `{"model":"mesh.obj","start":2,"end":2,"mode":"dijkstra"}`,
+			reason: "same-node paths are not cached"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.cacheSize != "" {
+				t.Setenv("CACHE_SIZE", tc.cacheSize)
+			}
+			projectRoot := newTestProject(t, "mesh.obj", 10)
+			engineRuns := 0
+			router := newTestRouter(appDeps{
+				resolveProjectRoot: func() (string, error) { return projectRoot, nil },
+				runEngine: func(_ context.Context, _, _ string, _ ...string) ([]byte, error) {
+					engineRuns++
+					return nil, nil
+				},
+				readFile: func(path string) ([]byte, error) { return []byte(`{"curves":[]}`), nil },
+			})
+
+			w := performRequest(router, http.MethodPost, "/warmup", tc.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var warm warmupResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &warm); err != nil {
+				t.Fatalf("failed to decode warmup response: %v", err)
+			}
+			if warm.Warmed || warm.Reason != tc.reason {
+				t.Fatalf("expected warmed false because %q, got %s", tc.reason, w.Body.String())
+			}
+			if engineRuns != 0 {
+				t.Fatalf("expected no engine run, got %d", engineRuns)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+#pragma once
+
+#include "../mesh/mesh.hpp"
+#include "dijkstra_result.hpp"
+
+// Distance estimates for A*. Euclidean never overestimates an edge-length
+// path, so its paths are shortest; Manhattan can, so it trades exactness for
+// fewer expansions. On a mesh with explicit weights neither applies and A*
+// searches as Dijkstra does.
+enum class AStarHeuristic {
+	Euclidean,
+	Manhattan,
+};
+
+DijkstraResult solveAStar(const Mesh &mesh, int start, int target,
+                          AStarHeuristic heuristic);
@@ -0,0 +1,11 @@
+#pragma once
+
+#include "dijkstra_result.hpp"
+
+// A Bellman-Ford run. When negativeCycleDetected is set a negative-weight
+// cycle is reachable from the start, so there is no shortest path and
+// shortest.reachable is false.
+struct BellmanFordResult {
+	DijkstraResult shortest;
+	bool negativeCycleDetected = false;
+};
@@ -0,0 +1,6 @@
+#pragma once
+
+#include "../mesh/mesh.hpp"
+#include "bellman_ford_result.hpp"
+
+BellmanFordResult solveBellmanFord(const Mesh &mesh, int start, int target);
@@ -0,0 +1,8 @@
+#pragma once
+
+#include "../mesh/mesh.hpp"
+#include "dijkstra_result.hpp"
+
+// Hop-count shortest path: every edge counts as 1 whatever its length, so
+// totalDistance and allDistances are numbers of edges.
+DijkstraResult solveBFS(const Mesh &mesh, int start, int target);
@@ -0,0 +1,19 @@
+#pragma once
+
+#include <vector>
+
+#include "../mesh/mesh.hpp"
+
+// eccentricities[v] is the longest shortest path from v to any vertex it can
+// reach, so on a disconnected model each part is measured on its own.
+// diameter is the largest eccentricity and endpoints the pair it runs
+// between.
+struct DiameterResult {
+	double diameter = 0.0;
+	int endpoints[2] = {0, 0};
+	std::vector<double> eccentricities;
+	double elapsedMs = 0.0;
+};
+
+// Runs Dijkstra from every vertex: exact, but quadratic in the vertex count.
+DiameterResult solveDiameter(const Mesh &mesh);
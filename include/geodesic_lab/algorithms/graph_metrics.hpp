@@ -0,0 +1,19 @@
+#pragma once
+
+#include "../mesh/mesh.hpp"
+
+// Graph measures analytics reports next to its curves. Weighted runs use
+// edge lengths; otherwise every edge counts as one hop.
+//
+// A vertex's closeness is the number of other vertices it reaches divided by
+// the sum of its distances to them, or 0 when it reaches none. pathLength is
+// negative when end is unreachable from start.
+struct GraphMetrics {
+	bool weighted = false;
+	double pathLength = -1.0;
+	double startCloseness = 0.0;
+	double endCloseness = 0.0;
+};
+
+GraphMetrics computeGraphMetrics(const Mesh &mesh, int start, int end,
+                                 bool weighted);
@@ -6,6 +6,9 @@
 #include "../types/face.hpp"
 #include "../types/vec3.hpp"
 
+// timeScale multiplies the diffusion time step, which is the squared mean
+// edge length at 1; larger values smooth the distance field more.
 AnalyticsCurve makeHeatMethodGeodesic(const std::vector<Vec3> &verts,
 									  const std::vector<Face> &faces,
-									  int startId, int endId);
+									  int startId, int endId,
+									  double timeScale = 1.0);
@@ -0,0 +1,12 @@
+#pragma once
+
+#include <vector>
+
+#include "../mesh/mesh.hpp"
+#include "dijkstra_result.hpp"
+
+// Up to k loopless paths from start to target, shortest first (Yen's
+// algorithm). Fewer come back when the graph has fewer distinct paths, and
+// none when target is unreachable. allDistances is left empty.
+std::vector<DijkstraResult> solveKShortestPaths(const Mesh &mesh, int start,
+                                                int target, int k);
@@ -0,0 +1,21 @@
+#pragma once
+
+#include <vector>
+
+#include "../mesh/mesh.hpp"
+
+struct MSTEdge {
+	int source;
+	int target;
+	double weight;
+};
+
+// A disconnected model yields a minimum spanning forest: one tree per
+// connected part.
+struct MSTResult {
+	std::vector<MSTEdge> edges;
+	double totalWeight = 0.0;
+	double elapsedMs = 0.0;
+};
+
+MSTResult solveMST(const Mesh &mesh);
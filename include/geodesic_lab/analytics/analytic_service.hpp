@@ -12,7 +12,10 @@ AnalyticsResult computeAnalyticsForModel(const std::string &inputFileName,
 										 const std::vector<Vec3> &objVertices,
 										 const std::vector<Face> &faces);
 
+// radius is in mean edge lengths and weight multiplies the resulting time
+// step; both 1 reproduces the default t = h^2.
 AnalyticsResult computeHeatForModel(const std::string &inputFileName,
 									int startId, int endId,
 									const std::vector<Vec3> &objVertices,
-									const std::vector<Face> &faces);
+									const std::vector<Face> &faces,
+									double radius = 1.0, double weight = 1.0);
@@ -19,5 +19,6 @@ struct AnalyticsResult {
 	std::vector<AnalyticsCurve> curves;
 	std::string error;
 	std::string surfaceParams; // raw JSON object string (e.g. {"center":[0,0,0],...})
+	std::string graphMetrics;  // raw JSON object string, analytics mode only
 	double elapsedMs = 0.0;
 };
@@ -2,8 +2,12 @@
 
 #include <string>
 
+#include "../algorithms/graph_metrics.hpp"
 #include "../analytics/analytic_types.hpp"
 
 void writeAnalyticsJSON(const std::string &outputFilename,
 						const std::string &outputPath,
 						const AnalyticsResult &res);
+
+// Formats metrics for AnalyticsResult::graphMetrics.
+std::string graphMetricsJSON(const GraphMetrics &metrics);
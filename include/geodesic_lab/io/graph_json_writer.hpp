@@ -0,0 +1,18 @@
+#pragma once
+
+#include <string>
+
+#include "../algorithms/diameter_solver.hpp"
+#include "../algorithms/mst_solver.hpp"
+
+// {"edges": [{"source", "target", "weight"}, ...], "totalWeight", ...}
+void writeMSTJSON(const std::string &outputFilename,
+				  const std::string &outputPath,
+				  const std::string &inputFileName,
+				  const MSTResult &res);
+
+// {"diameter", "endpoints": [a, b], "eccentricities": [...], ...}
+void writeDiameterJSON(const std::string &outputFilename,
+					   const std::string &outputPath,
+					   const std::string &inputFileName,
+					   const DiameterResult &res);
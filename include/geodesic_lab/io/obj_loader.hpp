@@ -6,7 +6,13 @@
 #include "../mesh/mesh.hpp"
 
 using EdgeConsumer = std::function<void(int, int)>;
+using WeightedEdgeConsumer = std::function<void(int, int, double)>;
 
+// Besides standard "v" and "f" lines, reads "e A B W" lines: an edge between
+// the 1-based vertices A and B with the non-negative weight W, which edge-list
+// models use in place of faces. Those go to onWeightedEdge and set
+// mesh.explicitWeights; without a consumer they are ignored.
 bool loadOBJIntoMesh(const std::string &filename, Mesh &mesh,
-                     const EdgeConsumer &onEdge);
+                     const EdgeConsumer &onEdge,
+                     const WeightedEdgeConsumer &onWeightedEdge = nullptr);
 
@@ -3,6 +3,7 @@
 #include <string>
 #include <vector>
 
+#include "../algorithms/bellman_ford_result.hpp"
 #include "../algorithms/dijkstra_result.hpp"
 
 void writeResultJSON(const std::string &outputFilename,
@@ -10,3 +11,14 @@ void writeResultJSON(const std::string &outputFilename,
 					 const std::string &inputFileName,
 					 const std::vector<double> &all_dists,
 					 const DijkstraResult &res);
+
+// result.json's shape plus "negativeCycleDetected".
+void writeBellmanFordJSON(const std::string &outputFilename,
+						  const std::string &outputPath,
+						  const std::string &inputFileName,
+						  const BellmanFordResult &res);
+
+// An array of {"totalDistance", "path"} objects, shortest first.
+void writeKShortestPathsJSON(const std::string &outputFilename,
+							 const std::string &outputPath,
+							 const std::vector<DijkstraResult> &paths);
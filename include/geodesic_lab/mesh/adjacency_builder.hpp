@@ -3,3 +3,9 @@
 #include "mesh.hpp"
 
 void addUndirectedEdge(Mesh &mesh, int v1_idx, int v2_idx);
+
+// Adds only the v1 -> v2 direction, for --directed runs.
+void addDirectedEdge(Mesh &mesh, int v1_idx, int v2_idx);
+
+// Adds v1 -> v2 with the given weight instead of its Euclidean length.
+void addWeightedEdge(Mesh &mesh, int v1_idx, int v2_idx, double weight);
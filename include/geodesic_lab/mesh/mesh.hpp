@@ -10,4 +10,7 @@ struct Mesh {
 	std::vector<Vec3> vertices;
 	std::vector<std::vector<Edge>> graph;
 	std::vector<Face> faces;
+	// Set when the model gives edge weights ("e" lines), so edge lengths no
+	// longer follow vertex positions.
+	bool explicitWeights = false;
 };
\ No newline at end of file
@@ -8,10 +8,18 @@
 #include <string>
 #include <vector>
 
+#include "include/geodesic_lab/algorithms/astar_solver.hpp"
+#include "include/geodesic_lab/algorithms/bellman_ford_solver.hpp"
+#include "include/geodesic_lab/algorithms/bfs_solver.hpp"
+#include "include/geodesic_lab/algorithms/diameter_solver.hpp"
 #include "include/geodesic_lab/algorithms/dijkstra_result.hpp"
 #include "include/geodesic_lab/algorithms/dijkstra_solver.hpp"
+#include "include/geodesic_lab/algorithms/graph_metrics.hpp"
+#include "include/geodesic_lab/algorithms/ksp_solver.hpp"
+#include "include/geodesic_lab/algorithms/mst_solver.hpp"
 #include "include/geodesic_lab/analytics/analytic_service.hpp"
 #include "include/geodesic_lab/io/analytics_json_writer.hpp"
+#include "include/geodesic_lab/io/graph_json_writer.hpp"
 #include "include/geodesic_lab/io/obj_loader.hpp"
 #include "include/geodesic_lab/io/result_json_writer.hpp"
 #include "include/geodesic_lab/mesh/adjacency_builder.hpp"
@@ -22,47 +30,162 @@ class MeshEngine {
   public:
 	Mesh mesh;
 
-	bool loadOBJ(const std::string &filename) {
-		return loadOBJIntoMesh(
+	// With directed set each face edge is only followed in the order the
+	// face lists its vertices, and each "e" edge from its first vertex.
+	bool loadOBJ(const std::string &filename, bool directed) {
+		const bool loaded = loadOBJIntoMesh(
 		    filename, mesh,
-		    [this](int v1_idx, int v2_idx) {
-			    addUndirectedEdge(mesh, v1_idx, v2_idx);
+		    [this, directed](int v1_idx, int v2_idx) {
+			    if (directed) {
+				    addDirectedEdge(mesh, v1_idx, v2_idx);
+			    } else {
+				    addUndirectedEdge(mesh, v1_idx, v2_idx);
+			    }
+		    },
+		    [this, directed](int v1_idx, int v2_idx, double weight) {
+			    addWeightedEdge(mesh, v1_idx, v2_idx, weight);
+			    if (!directed) {
+				    addWeightedEdge(mesh, v2_idx, v1_idx, weight);
+			    }
 		    });
+		// Vertices no face uses still need an (empty) adjacency list.
+		mesh.graph.resize(mesh.vertices.size());
+		return loaded;
 	}
 };
 
+namespace {
+
+// Parses a positional number argument, rejecting trailing garbage.
+bool parseNumber(const std::string &raw, double &out) {
+	try {
+		size_t used = 0;
+		out = std::stod(raw, &used);
+		return used == raw.size() && std::isfinite(out);
+	} catch (...) {
+		return false;
+	}
+}
+
+bool parseInt(const std::string &raw, int &out) {
+	try {
+		size_t used = 0;
+		out = std::stoi(raw, &used);
+		return used == raw.size();
+	} catch (...) {
+		return false;
+	}
+}
+
+double millisSince(std::chrono::high_resolution_clock::time_point t0) {
+	auto t1 = std::chrono::high_resolution_clock::now();
+	return std::chrono::duration<double, std::milli>(t1 - t0).count();
+}
+
+void printPathSummary(const std::string &title, const DijkstraResult &result) {
+	std::cout << "--- Geodesic Lab: " << title << " ---" << std::endl;
+	if (!result.reachable) {
+		std::cout << "Target Distance: (unreachable)" << std::endl;
+	} else {
+		std::cout << "Target Distance: " << result.totalDistance << std::endl;
+	}
+	std::cout << "Elapsed: " << result.elapsedMs << " ms" << std::endl;
+	std::cout << "Path: ";
+	for (int v : result.path)
+		std::cout << v << " ";
+	std::cout << "\n-----------------------------------" << std::endl;
+}
+
+} // namespace
+
 int main(int argc, char *argv[]) {
 
 	
-	// accept cmd line arguments
+	// accept cmd line arguments; "--output-dir <dir>" may appear anywhere and
+	// redirects the result JSON away from ./frontend/public/. "--directed"
+	// follows face edges one way only and "--weighted" weights analytics'
+	// graph metrics by edge length.
+	std::string outputPath = "./frontend/public/";
+	bool directed = false;
+	bool weighted = false;
+	std::vector<std::string> positional;
+	for (int i = 1; i < argc; ++i) {
+		std::string arg = argv[i];
+		if (arg == "--output-dir" && i + 1 < argc) {
+			outputPath = argv[++i];
+			if (outputPath.empty() || outputPath.back() != '/') {
+				outputPath += '/';
+			}
+		} else if (arg == "--directed") {
+			directed = true;
+		} else if (arg == "--weighted") {
+			weighted = true;
+		} else {
+			positional.push_back(arg);
+		}
+	}
 
-	if (argc < 4) {
-		std::cerr << "Usage: ./main <start_id> <end_id> <model_path> [mode]"
+	int startVertexIndex = 0;
+	int endVertexIndex = 0;
+	if (positional.size() < 3 || !parseInt(positional[0], startVertexIndex) ||
+	    !parseInt(positional[1], endVertexIndex)) {
+		std::cerr << "Usage: ./main <start_id> <end_id> <model_path> [mode [args]] [--directed] [--weighted] [--output-dir <dir>]"
 		          << std::endl;
 		std::cerr
 		    << "  mode: analytics (writes ./frontend/public/analytics.json)"
 		    << std::endl;
-		std::cerr << "  mode: heat (writes ./frontend/public/heat_result.json)"
+		std::cerr << "  mode: heat [RADIUS WEIGHT] (writes ./frontend/public/heat_result.json)"
+		          << std::endl;
+		std::cerr << "  mode: astar [euclidean|manhattan], bfs, bellman, ksp [K], mst, diameter"
+		          << " (write ./frontend/public/<mode>_result.json)" << std::endl;
+		std::cerr << "  --directed: follow face edges in vertex order only (path modes)"
+		          << std::endl;
+		std::cerr << "  --weighted: weight analytics' graph metrics by edge length"
+		          << std::endl;
+		std::cerr << "  --output-dir: write the result JSON into <dir> instead"
 		          << std::endl;
 		return 1;
 	}
 
-	int startVertexIndex = std::stoi(argv[1]);
-	int endVertexIndex = std::stoi(argv[2]);
-	std::string fileName = argv[3];
-	std::string mode = (argc >= 5) ? std::string(argv[4]) : std::string();
+	std::string fileName = positional[2];
+	std::string mode = (positional.size() >= 4) ? positional[3] : std::string();
+	const std::vector<std::string> modeArgs(
+	    positional.begin() + std::min<size_t>(positional.size(), 4), positional.end());
+
+	const bool pathMode = mode.empty() || mode == "astar" || mode == "bfs" ||
+	                      mode == "bellman" || mode == "ksp";
+	const bool wholeGraphMode = mode == "mst" || mode == "diameter";
+	if (!pathMode && !wholeGraphMode && mode != "analytics" && mode != "heat") {
+		std::cerr << "Error: unknown mode " << mode << std::endl;
+		return 1;
+	}
+	if (directed && !pathMode) {
+		std::cerr << "Error: --directed is not supported by " << mode << std::endl;
+		return 1;
+	}
+	if (weighted && mode != "analytics") {
+		std::cerr << "Error: --weighted is only supported by analytics" << std::endl;
+		return 1;
+	}
 
 	MeshEngine engine;
 	// std::string fileName = "./frontend/public/data/icosahedron.obj";
 
-	if (!engine.loadOBJ(fileName)) {
+	if (!engine.loadOBJ(fileName, directed)) {
 		std::cerr << "Error: Could not find " << fileName << std::endl;
 		return 1;
 	}
 
-	std::string outputPath = "./frontend/public/";
 	std::string inputFileName = fileName;
 
+	const int vertexCount = static_cast<int>(engine.mesh.vertices.size());
+	if (!wholeGraphMode && (startVertexIndex < 0 || endVertexIndex < 0 ||
+	                        startVertexIndex >= vertexCount || endVertexIndex >= vertexCount)) {
+		std::cerr << "Error: start and end must be vertex indices below " << vertexCount
+		          << std::endl;
+		return 1;
+	}
+
 
 	
 
@@ -73,6 +196,8 @@ int main(int argc, char *argv[]) {
 		    												engine.mesh.faces);
 		auto t1 = std::chrono::high_resolution_clock::now();
 		analytics.elapsedMs = std::chrono::duration<double, std::milli>(t1 - t0).count();
+		analytics.graphMetrics = graphMetricsJSON(
+		    computeGraphMetrics(engine.mesh, startVertexIndex, endVertexIndex, weighted));
 		writeAnalyticsJSON("analytics.json", outputPath, analytics);
 		std::cout << "--- Geodesic Lab: Analytics ---" << std::endl;
 		if (!analytics.error.empty()) {
@@ -86,9 +211,19 @@ int main(int argc, char *argv[]) {
 		return analytics.error.empty() ? 0 : 2;
 	}
 	else if (mode == "heat") { // heat method
+		// Optional RADIUS (> 0, in mean edge lengths) and WEIGHT (0-10]
+		double radius = 1.0;
+		double weight = 1.0;
+		if (!modeArgs.empty() &&
+		    (modeArgs.size() != 2 || !parseNumber(modeArgs[0], radius) ||
+		     !parseNumber(modeArgs[1], weight) || radius <= 0 || weight <= 0 || weight > 10)) {
+			std::cerr << "Error: heat takes RADIUS > 0 and WEIGHT in (0, 10]" << std::endl;
+			return 1;
+		}
 		auto t0 = std::chrono::high_resolution_clock::now();
 		AnalyticsResult heat = computeHeatForModel(inputFileName, startVertexIndex, endVertexIndex,
-		                        						engine.mesh.vertices, engine.mesh.faces);
+		                        						engine.mesh.vertices, engine.mesh.faces,
+		                        						radius, weight);
 		auto t1 = std::chrono::high_resolution_clock::now();
 		heat.elapsedMs = std::chrono::duration<double, std::milli>(t1 - t0).count();
 		writeAnalyticsJSON("heat_result.json", outputPath, heat);
@@ -103,6 +238,77 @@ int main(int argc, char *argv[]) {
 		std::cout << "--------------------------------" << std::endl;
 		return heat.error.empty() ? 0 : 2;
 	}
+	else if (mode == "astar") { // A* with a distance heuristic
+		AStarHeuristic heuristic = AStarHeuristic::Euclidean;
+		const std::string name = modeArgs.empty() ? "euclidean" : modeArgs[0];
+		if (name == "manhattan") {
+			heuristic = AStarHeuristic::Manhattan;
+		} else if (name != "euclidean") {
+			std::cerr << "Error: unknown heuristic " << name << std::endl;
+			return 1;
+		}
+		auto t0 = std::chrono::high_resolution_clock::now();
+		DijkstraResult result = solveAStar(engine.mesh, startVertexIndex, endVertexIndex, heuristic);
+		result.elapsedMs = millisSince(t0);
+		printPathSummary("A* Search", result);
+		writeResultJSON("astar_result.json", outputPath, inputFileName,
+			result.allDistances, result);
+	}
+	else if (mode == "bfs") { // hop-count shortest path
+		auto t0 = std::chrono::high_resolution_clock::now();
+		DijkstraResult result = solveBFS(engine.mesh, startVertexIndex, endVertexIndex);
+		result.elapsedMs = millisSince(t0);
+		printPathSummary("BFS", result);
+		writeResultJSON("bfs_result.json", outputPath, inputFileName,
+			result.allDistances, result);
+	}
+	else if (mode == "bellman") { // Bellman-Ford, tolerating negative weights
+		auto t0 = std::chrono::high_resolution_clock::now();
+		BellmanFordResult result = solveBellmanFord(engine.mesh, startVertexIndex, endVertexIndex);
+		result.shortest.elapsedMs = millisSince(t0);
+		printPathSummary("Bellman-Ford", result.shortest);
+		if (result.negativeCycleDetected) {
+			std::cout << "Negative cycle detected" << std::endl;
+		}
+		writeBellmanFordJSON("bellman_result.json", outputPath, inputFileName, result);
+	}
+	else if (mode == "ksp") { // K shortest loopless paths
+		int k = 3;
+		if (!modeArgs.empty() && (!parseInt(modeArgs[0], k) || k < 1 || k > 10)) {
+			std::cerr << "Error: ksp takes K between 1 and 10" << std::endl;
+			return 1;
+		}
+		auto t0 = std::chrono::high_resolution_clock::now();
+		std::vector<DijkstraResult> paths =
+		    solveKShortestPaths(engine.mesh, startVertexIndex, endVertexIndex, k);
+		std::cout << "--- Geodesic Lab: K Shortest Paths ---" << std::endl;
+		std::cout << "Paths: " << paths.size() << " of " << k << std::endl;
+		std::cout << "Elapsed: " << millisSince(t0) << " ms" << std::endl;
+		std::cout << "--------------------------------------" << std::endl;
+		writeKShortestPathsJSON("ksp_result.json", outputPath, paths);
+	}
+	else if (mode == "mst") { // minimum spanning tree; start and end unused
+		auto t0 = std::chrono::high_resolution_clock::now();
+		MSTResult mst = solveMST(engine.mesh);
+		mst.elapsedMs = millisSince(t0);
+		std::cout << "--- Geodesic Lab: Minimum Spanning Tree ---" << std::endl;
+		std::cout << "Edges: " << mst.edges.size() << std::endl;
+		std::cout << "Total Weight: " << mst.totalWeight << std::endl;
+		std::cout << "Elapsed: " << mst.elapsedMs << " ms" << std::endl;
+		std::cout << "-------------------------------------------" << std::endl;
+		writeMSTJSON("mst_result.json", outputPath, inputFileName, mst);
+	}
+	else if (mode == "diameter") { // graph diameter; start and end unused
+		auto t0 = std::chrono::high_resolution_clock::now();
+		DiameterResult diameter = solveDiameter(engine.mesh);
+		diameter.elapsedMs = millisSince(t0);
+		std::cout << "--- Geodesic Lab: Diameter ---" << std::endl;
+		std::cout << "Diameter: " << diameter.diameter << " (" << diameter.endpoints[0]
+		          << " - " << diameter.endpoints[1] << ")" << std::endl;
+		std::cout << "Elapsed: " << diameter.elapsedMs << " ms" << std::endl;
+		std::cout << "------------------------------" << std::endl;
+		writeDiameterJSON("diameter_result.json", outputPath, inputFileName, diameter);
+	}
 	else { // Dijkstra's method
 		auto t0 = std::chrono::high_resolution_clock::now();
 		DijkstraResult result = solveDijkstra(engine.mesh, startVertexIndex, endVertexIndex);
@@ -0,0 +1,72 @@
+#include "../../include/geodesic_lab/algorithms/astar_solver.hpp"
+
+#include <algorithm>
+#include <cmath>
+#include <limits>
+#include <queue>
+#include <vector>
+
+namespace {
+
+double estimate(const Mesh &mesh, int from, int to, AStarHeuristic heuristic) {
+	// Given weights need not follow positions, so no estimate is safe.
+	if (mesh.explicitWeights)
+		return 0.0;
+	const Vec3 &a = mesh.vertices[from];
+	const Vec3 &b = mesh.vertices[to];
+	const double dx = std::fabs(a.x - b.x);
+	const double dy = std::fabs(a.y - b.y);
+	const double dz = std::fabs(a.z - b.z);
+	if (heuristic == AStarHeuristic::Manhattan)
+		return dx + dy + dz;
+	return std::sqrt(dx * dx + dy * dy + dz * dz);
+}
+
+} // namespace
+
+DijkstraResult solveAStar(const Mesh &mesh, int start, int target,
+                          AStarHeuristic heuristic) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<double> min_dist(n, std::numeric_limits<double>::max());
+	std::vector<int> parent(n, -1);
+	std::vector<char> closed(n, 0);
+
+	// Queue entries are (distance so far + estimate to target, vertex).
+	min_dist[start] = 0;
+	std::priority_queue<std::pair<double, int>, std::vector<std::pair<double, int>>,
+	                    std::greater<>>
+	    pq;
+	pq.push({estimate(mesh, start, target, heuristic), start});
+
+	while (!pq.empty()) {
+		int u = pq.top().second;
+		pq.pop();
+
+		if (u == target)
+			break;
+		if (closed[u])
+			continue;
+		closed[u] = 1;
+
+		for (const auto &edge : mesh.graph[u]) {
+			const int v = edge.targetVertex;
+			if (min_dist[u] + edge.weight < min_dist[v]) {
+				min_dist[v] = min_dist[u] + edge.weight;
+				parent[v] = u;
+				closed[v] = 0;
+				pq.push({min_dist[v] + estimate(mesh, v, target, heuristic), v});
+			}
+		}
+	}
+
+	std::vector<int> path;
+	const bool reachable = (start == target) || (parent[target] != -1);
+	if (reachable) {
+		for (int v = target; v != -1; v = parent[v]) {
+			path.push_back(v);
+		}
+		std::reverse(path.begin(), path.end());
+	}
+
+	return {min_dist[target], reachable, path, min_dist};
+}
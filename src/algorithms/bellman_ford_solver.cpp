@@ -0,0 +1,59 @@
+#include "../../include/geodesic_lab/algorithms/bellman_ford_solver.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <vector>
+
+BellmanFordResult solveBellmanFord(const Mesh &mesh, int start, int target) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	const double unreached = std::numeric_limits<double>::max();
+	std::vector<double> min_dist(n, unreached);
+	std::vector<int> parent(n, -1);
+
+	min_dist[start] = 0;
+	// n - 1 rounds settle every shortest path; stop early once a round
+	// changes nothing.
+	bool changed = true;
+	for (int round = 0; round + 1 < n && changed; round++) {
+		changed = false;
+		for (int u = 0; u < n; u++) {
+			if (min_dist[u] == unreached)
+				continue;
+			for (const auto &edge : mesh.graph[u]) {
+				if (min_dist[u] + edge.weight < min_dist[edge.targetVertex]) {
+					min_dist[edge.targetVertex] = min_dist[u] + edge.weight;
+					parent[edge.targetVertex] = u;
+					changed = true;
+				}
+			}
+		}
+	}
+
+	BellmanFordResult result;
+	// Any edge that still relaxes lies on or behind a negative cycle.
+	for (int u = 0; u < n && changed && !result.negativeCycleDetected; u++) {
+		if (min_dist[u] == unreached)
+			continue;
+		for (const auto &edge : mesh.graph[u]) {
+			if (min_dist[u] + edge.weight < min_dist[edge.targetVertex]) {
+				result.negativeCycleDetected = true;
+				break;
+			}
+		}
+	}
+	if (result.negativeCycleDetected) {
+		result.shortest = {unreached, false, {}, min_dist};
+		return result;
+	}
+
+	std::vector<int> path;
+	const bool reachable = (start == target) || (parent[target] != -1);
+	if (reachable) {
+		for (int v = target; v != -1; v = parent[v]) {
+			path.push_back(v);
+		}
+		std::reverse(path.begin(), path.end());
+	}
+	result.shortest = {min_dist[target], reachable, path, min_dist};
+	return result;
+}
@@ -0,0 +1,42 @@
+#include "../../include/geodesic_lab/algorithms/bfs_solver.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <queue>
+#include <vector>
+
+DijkstraResult solveBFS(const Mesh &mesh, int start, int target) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<double> hops(n, std::numeric_limits<double>::max());
+	std::vector<int> parent(n, -1);
+
+	hops[start] = 0;
+	std::queue<int> frontier;
+	frontier.push(start);
+
+	// Unlike Dijkstra the whole component is visited, so allDistances is
+	// complete for every reachable vertex.
+	while (!frontier.empty()) {
+		int u = frontier.front();
+		frontier.pop();
+		for (const auto &edge : mesh.graph[u]) {
+			const int v = edge.targetVertex;
+			if (hops[v] == std::numeric_limits<double>::max()) {
+				hops[v] = hops[u] + 1;
+				parent[v] = u;
+				frontier.push(v);
+			}
+		}
+	}
+
+	std::vector<int> path;
+	const bool reachable = (start == target) || (parent[target] != -1);
+	if (reachable) {
+		for (int v = target; v != -1; v = parent[v]) {
+			path.push_back(v);
+		}
+		std::reverse(path.begin(), path.end());
+	}
+
+	return {hops[target], reachable, path, hops};
+}
@@ -0,0 +1,49 @@
+#include "../../include/geodesic_lab/algorithms/diameter_solver.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <queue>
+#include <vector>
+
+DiameterResult solveDiameter(const Mesh &mesh) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	const double unreached = std::numeric_limits<double>::max();
+
+	DiameterResult result;
+	result.eccentricities.assign(n, 0.0);
+	std::vector<double> min_dist(n);
+	for (int source = 0; source < n; source++) {
+		std::fill(min_dist.begin(), min_dist.end(), unreached);
+		min_dist[source] = 0;
+		std::priority_queue<std::pair<double, int>, std::vector<std::pair<double, int>>,
+		                    std::greater<>>
+		    pq;
+		pq.push({0, source});
+
+		while (!pq.empty()) {
+			double d = pq.top().first;
+			int u = pq.top().second;
+			pq.pop();
+			if (d > min_dist[u])
+				continue;
+
+			// Vertices leave the queue in distance order, so the last one
+			// settled is the farthest.
+			if (d > result.eccentricities[source]) {
+				result.eccentricities[source] = d;
+				if (d > result.diameter) {
+					result.diameter = d;
+					result.endpoints[0] = source;
+					result.endpoints[1] = u;
+				}
+			}
+			for (const auto &edge : mesh.graph[u]) {
+				if (d + edge.weight < min_dist[edge.targetVertex]) {
+					min_dist[edge.targetVertex] = d + edge.weight;
+					pq.push({min_dist[edge.targetVertex], edge.targetVertex});
+				}
+			}
+		}
+	}
+	return result;
+}
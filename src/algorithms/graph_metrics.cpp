@@ -0,0 +1,62 @@
+#include "../../include/geodesic_lab/algorithms/graph_metrics.hpp"
+
+#include <cstddef>
+#include <limits>
+#include <queue>
+#include <vector>
+
+namespace {
+
+// Distances from source to every vertex; unreachable ones stay at max().
+std::vector<double> distancesFrom(const Mesh &mesh, int source, bool weighted) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<double> min_dist(n, std::numeric_limits<double>::max());
+	min_dist[source] = 0;
+	std::priority_queue<std::pair<double, int>, std::vector<std::pair<double, int>>,
+	                    std::greater<>>
+	    pq;
+	pq.push({0, source});
+
+	while (!pq.empty()) {
+		double d = pq.top().first;
+		int u = pq.top().second;
+		pq.pop();
+		if (d > min_dist[u])
+			continue;
+		for (const auto &edge : mesh.graph[u]) {
+			const double w = weighted ? edge.weight : 1.0;
+			if (d + w < min_dist[edge.targetVertex]) {
+				min_dist[edge.targetVertex] = d + w;
+				pq.push({min_dist[edge.targetVertex], edge.targetVertex});
+			}
+		}
+	}
+	return min_dist;
+}
+
+double closeness(const std::vector<double> &dist, int source) {
+	double sum = 0.0;
+	int reached = 0;
+	for (std::size_t v = 0; v < dist.size(); v++) {
+		if (static_cast<int>(v) == source || dist[v] == std::numeric_limits<double>::max())
+			continue;
+		sum += dist[v];
+		reached++;
+	}
+	return (reached > 0 && sum > 0) ? reached / sum : 0.0;
+}
+
+} // namespace
+
+GraphMetrics computeGraphMetrics(const Mesh &mesh, int start, int end,
+                                 bool weighted) {
+	GraphMetrics metrics;
+	metrics.weighted = weighted;
+	const std::vector<double> fromStart = distancesFrom(mesh, start, weighted);
+	const std::vector<double> fromEnd = distancesFrom(mesh, end, weighted);
+	if (fromStart[end] != std::numeric_limits<double>::max())
+		metrics.pathLength = fromStart[end];
+	metrics.startCloseness = closeness(fromStart, start);
+	metrics.endCloseness = closeness(fromEnd, end);
+	return metrics;
+}
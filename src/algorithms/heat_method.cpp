@@ -28,7 +28,8 @@ double cotangent(const Vec3 &a, const Vec3 &b, const Vec3 &c) {
 
 AnalyticsCurve makeHeatMethodGeodesic(const std::vector<Vec3> &verts,
                                       const std::vector<Face> &faces,
-                                      int startId, int endId) {
+                                      int startId, int endId,
+                                      double timeScale) {
 	AnalyticsCurve c;
 	c.name = "heat_geodesic";
 	const int n = static_cast<int>(verts.size());
@@ -88,7 +89,7 @@ AnalyticsCurve makeHeatMethodGeodesic(const std::vector<Vec3> &verts,
 	}
 
 	const double h = (edgeCount > 0) ? (edgeSum / edgeCount) : 1.0;
-	const double t = h * h;
+	const double t = timeScale * h * h;
 
 	// Build Eigen sparse Laplacian L and mass matrix M
 	std::vector<Eigen::Triplet<double>> triplets;
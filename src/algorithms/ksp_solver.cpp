@@ -0,0 +1,132 @@
+#include "../../include/geodesic_lab/algorithms/ksp_solver.hpp"
+
+#include <algorithm>
+#include <limits>
+#include <queue>
+#include <set>
+#include <utility>
+#include <vector>
+
+namespace {
+
+// Candidate paths ordered by length, ties broken by vertex sequence so the
+// set also drops duplicates.
+using Candidate = std::pair<double, std::vector<int>>;
+
+// Dijkstra that skips banned vertices and banned directed edges. Returns an
+// empty path when target cannot be reached.
+Candidate shortestAvoiding(const Mesh &mesh, int start, int target,
+                           const std::vector<char> &bannedVertex,
+                           const std::set<std::pair<int, int>> &bannedEdge) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<double> min_dist(n, std::numeric_limits<double>::max());
+	std::vector<int> parent(n, -1);
+
+	min_dist[start] = 0;
+	std::priority_queue<std::pair<double, int>, std::vector<std::pair<double, int>>,
+	                    std::greater<>>
+	    pq;
+	pq.push({0, start});
+
+	while (!pq.empty()) {
+		double d = pq.top().first;
+		int u = pq.top().second;
+		pq.pop();
+
+		if (u == target)
+			break;
+		if (d > min_dist[u])
+			continue;
+
+		for (const auto &edge : mesh.graph[u]) {
+			const int v = edge.targetVertex;
+			if (bannedVertex[v] || bannedEdge.count({u, v}))
+				continue;
+			if (min_dist[u] + edge.weight < min_dist[v]) {
+				min_dist[v] = min_dist[u] + edge.weight;
+				parent[v] = u;
+				pq.push({min_dist[v], v});
+			}
+		}
+	}
+
+	if (start != target && parent[target] == -1)
+		return {0.0, {}};
+	std::vector<int> path;
+	for (int v = target; v != -1; v = parent[v]) {
+		path.push_back(v);
+	}
+	std::reverse(path.begin(), path.end());
+	return {min_dist[target], path};
+}
+
+// Weight of the lightest u -> v edge; meshes list shared edges once per face.
+double edgeWeight(const Mesh &mesh, int u, int v) {
+	double best = std::numeric_limits<double>::max();
+	for (const auto &edge : mesh.graph[u]) {
+		if (edge.targetVertex == v)
+			best = std::min(best, edge.weight);
+	}
+	return best;
+}
+
+} // namespace
+
+std::vector<DijkstraResult> solveKShortestPaths(const Mesh &mesh, int start,
+                                                int target, int k) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<Candidate> found;
+	if (k <= 0)
+		return {};
+
+	const std::vector<char> noVertices(n, 0);
+	Candidate first = shortestAvoiding(mesh, start, target, noVertices, {});
+	if (first.second.empty())
+		return {};
+	found.push_back(std::move(first));
+
+	std::set<Candidate> candidates;
+	while (static_cast<int>(found.size()) < k) {
+		const std::vector<int> &last = found.back().second;
+		// Branch off the previous path at each of its vertices in turn,
+		// keeping its prefix and forbidding the edges earlier paths took
+		// from there.
+		double rootCost = 0.0;
+		for (size_t i = 0; i + 1 < last.size(); i++) {
+			const int spur = last[i];
+			const std::vector<int> root(last.begin(), last.begin() + i + 1);
+
+			std::set<std::pair<int, int>> bannedEdge;
+			for (const auto &p : found) {
+				if (p.second.size() > i + 1 &&
+				    std::equal(root.begin(), root.end(), p.second.begin())) {
+					bannedEdge.insert({p.second[i], p.second[i + 1]});
+				}
+			}
+			std::vector<char> bannedVertex(n, 0);
+			for (size_t j = 0; j < i; j++) {
+				bannedVertex[root[j]] = 1;
+			}
+
+			Candidate spurPath = shortestAvoiding(mesh, spur, target, bannedVertex, bannedEdge);
+			if (!spurPath.second.empty()) {
+				std::vector<int> total = root;
+				total.insert(total.end(), spurPath.second.begin() + 1, spurPath.second.end());
+				candidates.insert({rootCost + spurPath.first, std::move(total)});
+			}
+			rootCost += edgeWeight(mesh, last[i], last[i + 1]);
+		}
+
+		if (candidates.empty())
+			break;
+		found.push_back(*candidates.begin());
+		candidates.erase(candidates.begin());
+	}
+
+	std::vector<DijkstraResult> paths;
+	paths.reserve(found.size());
+	for (auto &p : found) {
+		paths.push_back({p.first, true, std::move(p.second), {}});
+	}
+	return paths;
+}
@@ -0,0 +1,64 @@
+#include "../../include/geodesic_lab/algorithms/mst_solver.hpp"
+
+#include <algorithm>
+#include <numeric>
+#include <vector>
+
+namespace {
+
+// Union-find over vertex indices with path halving.
+struct DisjointSets {
+	std::vector<int> parent;
+
+	explicit DisjointSets(int n) : parent(n) {
+		std::iota(parent.begin(), parent.end(), 0);
+	}
+
+	int find(int v) {
+		while (parent[v] != v) {
+			parent[v] = parent[parent[v]];
+			v = parent[v];
+		}
+		return v;
+	}
+
+	bool unite(int a, int b) {
+		a = find(a);
+		b = find(b);
+		if (a == b)
+			return false;
+		parent[b] = a;
+		return true;
+	}
+};
+
+} // namespace
+
+// Kruskal's algorithm over the undirected edges of the mesh.
+MSTResult solveMST(const Mesh &mesh) {
+	const int n = static_cast<int>(mesh.vertices.size());
+	std::vector<MSTEdge> edges;
+	for (int u = 0; u < n; u++) {
+		for (const auto &edge : mesh.graph[u]) {
+			if (u < edge.targetVertex)
+				edges.push_back({u, edge.targetVertex, edge.weight});
+		}
+	}
+	std::sort(edges.begin(), edges.end(), [](const MSTEdge &a, const MSTEdge &b) {
+		if (a.weight != b.weight)
+			return a.weight < b.weight;
+		if (a.source != b.source)
+			return a.source < b.source;
+		return a.target < b.target;
+	});
+
+	MSTResult result;
+	DisjointSets sets(n);
+	for (const auto &edge : edges) {
+		if (sets.unite(edge.source, edge.target)) {
+			result.edges.push_back(edge);
+			result.totalWeight += edge.weight;
+		}
+	}
+	return result;
+}
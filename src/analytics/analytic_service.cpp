@@ -128,7 +128,8 @@ AnalyticsResult computeAnalyticsForModel(const std::string &inputFileName,
 AnalyticsResult computeHeatForModel(const std::string &inputFileName,
                                     int startId, int endId,
                                     const std::vector<Vec3> &objVertices,
-                                    const std::vector<Face> &faces) {
+                                    const std::vector<Face> &faces,
+                                    double radius, double weight) {
 	AnalyticsResult out;
 	out.inputFileName = inputFileName;
 	out.startId = startId;
@@ -158,7 +159,8 @@ AnalyticsResult computeHeatForModel(const std::string &inputFileName,
 	const double lengthScale = (t.scale > 1e-12) ? (1.0 / t.scale) : 1.0;
 
 	AnalyticsCurve heat =
-	    makeHeatMethodGeodesic(normalizedVerts, faces, startId, endId);
+	    makeHeatMethodGeodesic(normalizedVerts, faces, startId, endId,
+	                           weight * radius * radius);
 	if (heat.points.empty()) {
 		out.error = "Heat method failed to produce a path";
 		return out;
@@ -2,6 +2,7 @@
 
 #include <fstream>
 #include <iostream>
+#include <sstream>
 
 #include "../../include/geodesic_lab/io/json_escape.hpp"
 
@@ -25,6 +26,9 @@ void writeAnalyticsJSON(const std::string &outputFilename,
 	file << "  \"surfaceParams\": "
 	     << (res.surfaceParams.empty() ? "null" : res.surfaceParams)
 	     << ",\n";
+	if (!res.graphMetrics.empty()) {
+		file << "  \"graphMetrics\": " << res.graphMetrics << ",\n";
+	}
 	file << "  \"elapsedMs\": " << res.elapsedMs << ",\n";
 	file << "  \"error\": \"" << jsonEscape(res.error) << "\",\n";
 	file << "  \"curves\": [\n";
@@ -49,3 +53,17 @@ void writeAnalyticsJSON(const std::string &outputFilename,
 	file << "  ]\n";
 	file << "}\n";
 }
+
+std::string graphMetricsJSON(const GraphMetrics &metrics) {
+	std::ostringstream oss;
+	oss << "{\"weighted\":" << (metrics.weighted ? "true" : "false") << ",";
+	oss << "\"pathLength\":";
+	if (metrics.pathLength < 0) {
+		oss << "null";
+	} else {
+		oss << metrics.pathLength;
+	}
+	oss << ",\"startCloseness\":" << metrics.startCloseness
+	    << ",\"endCloseness\":" << metrics.endCloseness << "}";
+	return oss.str();
+}
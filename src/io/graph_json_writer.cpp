@@ -0,0 +1,58 @@
+#include "../../include/geodesic_lab/io/graph_json_writer.hpp"
+
+#include <fstream>
+#include <iostream>
+
+#include "../../include/geodesic_lab/io/json_escape.hpp"
+
+void writeMSTJSON(const std::string &outputFilename,
+                  const std::string &outputPath,
+                  const std::string &inputFileName,
+                  const MSTResult &res) {
+	const std::string fullPath = outputPath + outputFilename;
+	std::ofstream file(fullPath);
+	if (!file.is_open()) {
+		std::cerr << "Error: Could not write " << fullPath << std::endl;
+		return;
+	}
+
+	file << "{\n";
+	file << "  \"inputFileName\": \"" << jsonEscape(inputFileName)
+	     << "\",\n";
+	file << "  \"totalWeight\": " << res.totalWeight << ",\n";
+	file << "  \"elapsedMs\": " << res.elapsedMs << ",\n";
+	file << "  \"edges\": [";
+	for (size_t i = 0; i < res.edges.size(); ++i) {
+		const auto &e = res.edges[i];
+		file << "{\"source\": " << e.source << ", \"target\": " << e.target
+		     << ", \"weight\": " << e.weight << "}"
+		     << (i + 1 < res.edges.size() ? ", " : "");
+	}
+	file << "]\n}\n";
+}
+
+void writeDiameterJSON(const std::string &outputFilename,
+                       const std::string &outputPath,
+                       const std::string &inputFileName,
+                       const DiameterResult &res) {
+	const std::string fullPath = outputPath + outputFilename;
+	std::ofstream file(fullPath);
+	if (!file.is_open()) {
+		std::cerr << "Error: Could not write " << fullPath << std::endl;
+		return;
+	}
+
+	file << "{\n";
+	file << "  \"inputFileName\": \"" << jsonEscape(inputFileName)
+	     << "\",\n";
+	file << "  \"diameter\": " << res.diameter << ",\n";
+	file << "  \"endpoints\": [" << res.endpoints[0] << ", "
+	     << res.endpoints[1] << "],\n";
+	file << "  \"elapsedMs\": " << res.elapsedMs << ",\n";
+	file << "  \"eccentricities\": [";
+	for (size_t i = 0; i < res.eccentricities.size(); ++i) {
+		file << res.eccentricities[i]
+		     << (i + 1 < res.eccentricities.size() ? ", " : "");
+	}
+	file << "]\n}\n";
+}
@@ -6,7 +6,8 @@
 #include <vector>
 
 bool loadOBJIntoMesh(const std::string &filename, Mesh &mesh,
-                     const EdgeConsumer &onEdge) {
+                     const EdgeConsumer &onEdge,
+                     const WeightedEdgeConsumer &onWeightedEdge) {
 	std::ifstream file(filename);
 	if (!file.is_open())
 		return false;
@@ -14,6 +15,25 @@ bool loadOBJIntoMesh(const std::string &filename, Mesh &mesh,
 	mesh.vertices.clear();
 	mesh.graph.clear();
 	mesh.faces.clear();
+	mesh.explicitWeights = false;
+
+	auto toIndex = [&](const std::string &t) -> int {
+		const auto slash = t.find('/');
+		const std::string head =
+		    (slash == std::string::npos) ? t : t.substr(0, slash);
+		int idx = 0;
+		try {
+			idx = std::stoi(head);
+		} catch (...) {
+			return -1;
+		}
+		if (idx == 0)
+			return -1;
+		int resolved =
+		    (idx > 0) ? (idx - 1)
+		              : (static_cast<int>(mesh.vertices.size()) + idx);
+		return resolved;
+	};
 
 	std::string line;
 	while (std::getline(file, line)) {
@@ -34,24 +54,6 @@ bool loadOBJIntoMesh(const std::string &filename, Mesh &mesh,
 			if (tokens.size() < 3)
 				continue;
 
-			auto toIndex = [&](const std::string &t) -> int {
-				const auto slash = t.find('/');
-				const std::string head =
-				    (slash == std::string::npos) ? t : t.substr(0, slash);
-				int idx = 0;
-				try {
-					idx = std::stoi(head);
-				} catch (...) {
-					return -1;
-				}
-				if (idx == 0)
-					return -1;
-				int resolved =
-				    (idx > 0) ? (idx - 1)
-				              : (static_cast<int>(mesh.vertices.size()) + idx);
-				return resolved;
-			};
-
 			std::vector<int> faceIndices;
 			faceIndices.reserve(tokens.size());
 			for (const auto &t : tokens) {
@@ -75,6 +77,19 @@ bool loadOBJIntoMesh(const std::string &filename, Mesh &mesh,
 				onEdge(c, a);
 			}
 		}
+		else if (type == "e" && onWeightedEdge) {
+			std::string a, b;
+			double weight = -1.0;
+			if (!(iss >> a >> b >> weight) || !(weight >= 0.0))
+				continue;
+			const int from = toIndex(a);
+			const int to = toIndex(b);
+			const int n = static_cast<int>(mesh.vertices.size());
+			if (from < 0 || to < 0 || from >= n || to >= n)
+				continue;
+			mesh.explicitWeights = true;
+			onWeightedEdge(from, to, weight);
+		}
 	}
 
 	return true;
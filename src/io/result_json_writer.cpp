@@ -7,11 +7,33 @@
 
 #include "../../include/geodesic_lab/io/json_escape.hpp"
 
-void writeResultJSON(const std::string &outputFilename,
+namespace {
+
+void writeDistance(std::ostream &file, const DijkstraResult &res) {
+	if (!res.reachable || !std::isfinite(res.totalDistance) ||
+	    res.totalDistance >= std::numeric_limits<double>::max() / 2) {
+		file << "null";
+	} else {
+		file << res.totalDistance;
+	}
+}
+
+void writePath(std::ostream &file, const std::vector<int> &path) {
+	file << "[";
+	for (size_t i = 0; i < path.size(); ++i) {
+		file << path[i] << (i == path.size() - 1 ? "" : ", ");
+	}
+	file << "]";
+}
+
+// Writes result.json's fields; extraFields, if any, are inserted before
+// allDistances and must end with ",\n".
+void writeResultFile(const std::string &outputFilename,
                      const std::string &outputPath,
                      const std::string &inputFileName,
                      const std::vector<double> &all_dists,
-                     const DijkstraResult &res) {
+                     const DijkstraResult &res,
+                     const std::string &extraFields) {
 	const std::string full_path = outputPath + outputFilename;
 	std::ofstream file(full_path);
 	if (!file.is_open()) {
@@ -25,22 +47,58 @@ void writeResultJSON(const std::string &outputFilename,
 	file << "  \"reachable\": " << (res.reachable ? "true" : "false")
 	     << ",\n";
 	file << "  \"totalDistance\": ";
-	if (!res.reachable || !std::isfinite(res.totalDistance) ||
-	    res.totalDistance >= std::numeric_limits<double>::max() / 2) {
-		file << "null";
-	} else {
-		file << res.totalDistance;
-	}
+	writeDistance(file, res);
+	file << ",\n";
+	file << "  \"path\": ";
+	writePath(file, res.path);
 	file << ",\n";
-	file << "  \"path\": [";
-	for (size_t i = 0; i < res.path.size(); ++i) {
-		file << res.path[i] << (i == res.path.size() - 1 ? "" : ", ");
-	}
-	file << "],\n";
 	file << "  \"elapsedMs\": " << res.elapsedMs << ",\n";
+	file << extraFields;
 	file << "  \"allDistances\": [";
 	for (size_t i = 0; i < all_dists.size(); ++i) {
 		file << all_dists[i] << (i == all_dists.size() - 1 ? "" : ", ");
 	}
 	file << "]\n}";
 }
+
+} // namespace
+
+void writeResultJSON(const std::string &outputFilename,
+                     const std::string &outputPath,
+                     const std::string &inputFileName,
+                     const std::vector<double> &all_dists,
+                     const DijkstraResult &res) {
+	writeResultFile(outputFilename, outputPath, inputFileName, all_dists, res, "");
+}
+
+void writeBellmanFordJSON(const std::string &outputFilename,
+                          const std::string &outputPath,
+                          const std::string &inputFileName,
+                          const BellmanFordResult &res) {
+	const std::string flag = std::string("  \"negativeCycleDetected\": ") +
+	                         (res.negativeCycleDetected ? "true" : "false") + ",\n";
+	writeResultFile(outputFilename, outputPath, inputFileName,
+	                res.shortest.allDistances, res.shortest, flag);
+}
+
+void writeKShortestPathsJSON(const std::string &outputFilename,
+                             const std::string &outputPath,
+                             const std::vector<DijkstraResult> &paths) {
+	const std::string full_path = outputPath + outputFilename;
+	std::ofstream file(full_path);
+	if (!file.is_open()) {
+		std::cerr << "Error: Could not write " << full_path << std::endl;
+		return;
+	}
+
+	file << "[";
+	for (size_t i = 0; i < paths.size(); ++i) {
+		file << (i == 0 ? "\n" : ",\n");
+		file << "  {\"totalDistance\": ";
+		writeDistance(file, paths[i]);
+		file << ", \"path\": ";
+		writePath(file, paths[i].path);
+		file << "}";
+	}
+	file << (paths.empty() ? "]" : "\n]");
+}
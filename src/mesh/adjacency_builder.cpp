@@ -12,14 +12,29 @@ double edgeDistance(const Vec3 &v1, const Vec3 &v2) {
 	return std::sqrt(dx * dx + dy * dy + dz * dz);
 }
 
-} // namespace
-
-void addUndirectedEdge(Mesh &mesh, int v1_idx, int v2_idx) {
+void growGraph(Mesh &mesh, int v1_idx, int v2_idx) {
 	const int maxIndex = std::max(v1_idx, v2_idx);
 	if (maxIndex >= 0 && mesh.graph.size() <= static_cast<size_t>(maxIndex)) {
 		mesh.graph.resize(static_cast<size_t>(maxIndex) + 1);
 	}
+}
+
+} // namespace
+
+void addUndirectedEdge(Mesh &mesh, int v1_idx, int v2_idx) {
+	growGraph(mesh, v1_idx, v2_idx);
 	const double d = edgeDistance(mesh.vertices[v1_idx], mesh.vertices[v2_idx]);
 	mesh.graph[v1_idx].push_back({v2_idx, d});
 	mesh.graph[v2_idx].push_back({v1_idx, d});
 }
+
+void addDirectedEdge(Mesh &mesh, int v1_idx, int v2_idx) {
+	growGraph(mesh, v1_idx, v2_idx);
+	const double d = edgeDistance(mesh.vertices[v1_idx], mesh.vertices[v2_idx]);
+	mesh.graph[v1_idx].push_back({v2_idx, d});
+}
+
+void addWeightedEdge(Mesh &mesh, int v1_idx, int v2_idx, double weight) {
+	growGraph(mesh, v1_idx, v2_idx);
+	mesh.graph[v1_idx].push_back({v2_idx, weight});
+}
@@ -0,0 +1,102 @@
+// Unit tests for the whole-graph solvers: MST, diameter and graph metrics.
+
+#include <gtest/gtest.h>
+
+#include <algorithm>
+#include <cmath>
+#include <utility>
+#include <vector>
+
+#include "geodesic_lab/algorithms/diameter_solver.hpp"
+#include "geodesic_lab/algorithms/graph_metrics.hpp"
+#include "geodesic_lab/algorithms/mst_solver.hpp"
+#include "geodesic_lab/mesh/adjacency_builder.hpp"
+#include "geodesic_lab/mesh/mesh.hpp"
+
+namespace {
+
+Mesh makeMesh(const std::vector<Vec3> &vertices,
+		      const std::vector<std::pair<int, int>> &edges) {
+	Mesh mesh;
+	mesh.vertices = vertices;
+	mesh.graph.resize(vertices.size());
+	for (const auto &edge : edges) {
+		addUndirectedEdge(mesh, edge.first, edge.second);
+	}
+	return mesh;
+}
+
+// A unit square 0-1-2-3 with the 0-2 diagonal.
+Mesh makeSquare() {
+	return makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {1.0, 1.0, 0.0}, {0.0, 1.0, 0.0}},
+	    {{0, 1}, {1, 2}, {2, 3}, {3, 0}, {0, 2}});
+}
+
+} // namespace
+
+
+
+// Tests that the MST keeps the cheapest edges that connect every vertex.
+TEST(MSTSolverTest, SpansSquareWithItsSides) {
+	const MSTResult mst = solveMST(makeSquare());
+
+	ASSERT_EQ(mst.edges.size(), 3u);
+	EXPECT_NEAR(mst.totalWeight, 3.0, 1e-12);
+	for (const MSTEdge &edge : mst.edges) {
+		EXPECT_LT(edge.source, edge.target);
+		EXPECT_NEAR(edge.weight, 1.0, 1e-12);
+	}
+}
+
+// Tests that a disconnected graph yields a spanning forest.
+TEST(MSTSolverTest, DisconnectedGraphYieldsForest) {
+	const Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {5.0, 0.0, 0.0}, {7.0, 0.0, 0.0}},
+	    {{0, 1}, {2, 3}});
+
+	const MSTResult mst = solveMST(mesh);
+
+	EXPECT_EQ(mst.edges.size(), 2u);
+	EXPECT_NEAR(mst.totalWeight, 3.0, 1e-12);
+}
+
+// Tests that the diameter runs between the square's off-diagonal corners.
+TEST(DiameterSolverTest, FindsLongestShortestPath) {
+	const DiameterResult result = solveDiameter(makeSquare());
+
+	EXPECT_NEAR(result.diameter, 2.0, 1e-12);
+	const int a = std::min(result.endpoints[0], result.endpoints[1]);
+	const int b = std::max(result.endpoints[0], result.endpoints[1]);
+	EXPECT_EQ(a, 1);
+	EXPECT_EQ(b, 3);
+	ASSERT_EQ(result.eccentricities.size(), 4u);
+	EXPECT_NEAR(result.eccentricities[0], std::sqrt(2.0), 1e-12);
+}
+
+// Tests that weighted metrics use edge lengths and unweighted ones count hops.
+TEST(GraphMetricsTest, WeightedUsesEdgeLengths) {
+	const Mesh mesh = makeSquare();
+
+	const GraphMetrics weighted = computeGraphMetrics(mesh, 0, 2, true);
+	const GraphMetrics hops = computeGraphMetrics(mesh, 0, 2, false);
+
+	EXPECT_TRUE(weighted.weighted);
+	EXPECT_NEAR(weighted.pathLength, std::sqrt(2.0), 1e-12);
+	EXPECT_FALSE(hops.weighted);
+	EXPECT_DOUBLE_EQ(hops.pathLength, 1.0);
+	// Vertex 0 is one hop from all three others.
+	EXPECT_DOUBLE_EQ(hops.startCloseness, 1.0);
+}
+
+// Tests that an unreachable end leaves pathLength negative.
+TEST(GraphMetricsTest, UnreachableEndHasNegativePathLength) {
+	const Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {5.0, 0.0, 0.0}}, {{0, 1}});
+
+	const GraphMetrics metrics = computeGraphMetrics(mesh, 0, 2, false);
+
+	EXPECT_LT(metrics.pathLength, 0.0);
+	EXPECT_DOUBLE_EQ(metrics.startCloseness, 1.0);
+	EXPECT_DOUBLE_EQ(metrics.endCloseness, 0.0);
+}
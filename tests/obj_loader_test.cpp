@@ -6,6 +6,8 @@
 #include <fstream>
 #include <string>
 #include <system_error>
+#include <utility>
+#include <vector>
 
 #include "geodesic_lab/io/obj_loader.hpp"
 #include "geodesic_lab/mesh/adjacency_builder.hpp"
@@ -131,3 +133,49 @@ f 1 2 nope
 	EXPECT_EQ(mesh.vertices.size(), 3u);
 	EXPECT_TRUE(mesh.faces.empty());
 }
+
+TEST(ObjLoaderTest, ReadsWeightedEdgeLines) {
+	TempObjFile file(R"OBJ(
+v 0 0 0
+v 1 0 0
+v 2 0 0
+e 1 2 5
+e 2 3 0.5
+e 1 3 -1
+e 1 9 2
+)OBJ");
+
+	Mesh mesh;
+	std::vector<std::pair<int, int>> edges;
+	std::vector<double> weights;
+	ASSERT_TRUE(loadOBJIntoMesh(
+	    file.path().string(), mesh,
+	    [&](int a, int b) {
+		addUndirectedEdge(mesh, a, b);
+	    },
+	    [&](int a, int b, double w) {
+		edges.push_back({a, b});
+		weights.push_back(w);
+	    }));
+
+	EXPECT_TRUE(mesh.explicitWeights);
+	EXPECT_TRUE(mesh.faces.empty());
+	const std::vector<std::pair<int, int>> expectedEdges{{0, 1}, {1, 2}};
+	EXPECT_EQ(edges, expectedEdges);
+	const std::vector<double> expectedWeights{5.0, 0.5};
+	EXPECT_EQ(weights, expectedWeights);
+}
+
+TEST(ObjLoaderTest, IgnoresWeightedEdgesWithoutConsumer) {
+	TempObjFile file(R"OBJ(
+v 0 0 0
+v 1 0 0
+e 1 2 5
+)OBJ");
+
+	Mesh mesh;
+	ASSERT_TRUE(loadObj(file.path(), mesh));
+
+	EXPECT_FALSE(mesh.explicitWeights);
+	EXPECT_TRUE(mesh.graph.empty());
+}
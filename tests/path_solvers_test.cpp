@@ -0,0 +1,169 @@
+// Unit tests for the A*, BFS, Bellman-Ford and K-shortest-paths solvers.
+
+#include <gtest/gtest.h>
+
+#include <cmath>
+#include <tuple>
+#include <utility>
+#include <vector>
+
+#include "geodesic_lab/algorithms/astar_solver.hpp"
+#include "geodesic_lab/algorithms/bellman_ford_solver.hpp"
+#include "geodesic_lab/algorithms/bfs_solver.hpp"
+#include "geodesic_lab/algorithms/dijkstra_solver.hpp"
+#include "geodesic_lab/algorithms/ksp_solver.hpp"
+#include "geodesic_lab/mesh/adjacency_builder.hpp"
+#include "geodesic_lab/mesh/mesh.hpp"
+
+namespace {
+
+Mesh makeMesh(const std::vector<Vec3> &vertices,
+		      const std::vector<std::pair<int, int>> &edges,
+		      bool directed = false) {
+	Mesh mesh;
+	mesh.vertices = vertices;
+	mesh.graph.resize(vertices.size());
+	for (const auto &edge : edges) {
+		if (directed) {
+			addDirectedEdge(mesh, edge.first, edge.second);
+		} else {
+			addUndirectedEdge(mesh, edge.first, edge.second);
+		}
+	}
+	return mesh;
+}
+
+// A unit square 0-1-2-3 with the 0-2 diagonal.
+Mesh makeSquare(bool directed = false) {
+	return makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {1.0, 1.0, 0.0}, {0.0, 1.0, 0.0}},
+	    {{0, 1}, {1, 2}, {2, 3}, {3, 0}, {0, 2}}, directed);
+}
+
+} // namespace
+
+
+
+// Tests that A* with the Euclidean heuristic finds the same path as Dijkstra.
+TEST(AStarSolverTest, EuclideanMatchesDijkstra) {
+	const Mesh mesh = makeSquare();
+
+	const DijkstraResult astar = solveAStar(mesh, 1, 3, AStarHeuristic::Euclidean);
+	const DijkstraResult dijkstra = solveDijkstra(mesh, 1, 3);
+
+	ASSERT_TRUE(astar.reachable);
+	EXPECT_NEAR(astar.totalDistance, dijkstra.totalDistance, 1e-12);
+	EXPECT_EQ(astar.path.front(), 1);
+	EXPECT_EQ(astar.path.back(), 3);
+}
+
+// Tests that A* stays exact when given weights do not follow positions.
+TEST(AStarSolverTest, ExplicitWeightsDisableTheHeuristic) {
+	Mesh mesh;
+	mesh.vertices = {{0.0, 0.0, 0.0}, {100.0, 0.0, 0.0}, {1.0, 0.0, 0.0}};
+	mesh.graph.resize(3);
+	mesh.explicitWeights = true;
+	// 0-1-2 costs 2 although vertex 1 lies far away; 0-2 directly costs 5.
+	for (const auto &[a, b, w] : {std::tuple{0, 1, 1.0}, {1, 2, 1.0}, {0, 2, 5.0}}) {
+		addWeightedEdge(mesh, a, b, w);
+		addWeightedEdge(mesh, b, a, w);
+	}
+
+	const DijkstraResult result = solveAStar(mesh, 0, 2, AStarHeuristic::Euclidean);
+
+	const std::vector<int> expectedPath{0, 1, 2};
+	EXPECT_EQ(result.path, expectedPath);
+	EXPECT_DOUBLE_EQ(result.totalDistance, 2.0);
+}
+
+// Tests that A* reports an unreachable target with an empty path.
+TEST(AStarSolverTest, DisconnectedGraphReturnsUnreachable) {
+	const Mesh mesh =
+	    makeMesh({{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {5.0, 0.0, 0.0}}, {{0, 1}});
+
+	const DijkstraResult result = solveAStar(mesh, 0, 2, AStarHeuristic::Manhattan);
+
+	EXPECT_FALSE(result.reachable);
+	EXPECT_TRUE(result.path.empty());
+}
+
+// Tests that BFS minimises hops rather than length.
+TEST(BFSSolverTest, CountsHopsNotLength) {
+	// 0-3 directly is long; 0-1-2-3 is short but takes three hops.
+	const Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {2.0, 0.0, 0.0}, {30.0, 0.0, 0.0}},
+	    {{0, 1}, {1, 2}, {2, 3}, {0, 3}});
+
+	const DijkstraResult result = solveBFS(mesh, 0, 3);
+
+	ASSERT_TRUE(result.reachable);
+	const std::vector<int> expectedPath{0, 3};
+	EXPECT_EQ(result.path, expectedPath);
+	EXPECT_DOUBLE_EQ(result.totalDistance, 1.0);
+	ASSERT_EQ(result.allDistances.size(), 4u);
+	EXPECT_DOUBLE_EQ(result.allDistances[2], 2.0);
+}
+
+// Tests that a directed mesh is only walked along its edges' direction.
+TEST(BFSSolverTest, DirectedEdgesAreOneWay) {
+	const Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {2.0, 0.0, 0.0}},
+	    {{0, 1}, {1, 2}}, true);
+
+	EXPECT_TRUE(solveBFS(mesh, 0, 2).reachable);
+	EXPECT_FALSE(solveBFS(mesh, 2, 0).reachable);
+}
+
+// Tests that Bellman-Ford agrees with Dijkstra on non-negative weights.
+TEST(BellmanFordSolverTest, MatchesDijkstraWithoutNegativeWeights) {
+	const Mesh mesh = makeSquare();
+
+	const BellmanFordResult result = solveBellmanFord(mesh, 0, 2);
+
+	EXPECT_FALSE(result.negativeCycleDetected);
+	ASSERT_TRUE(result.shortest.reachable);
+	const std::vector<int> expectedPath{0, 2};
+	EXPECT_EQ(result.shortest.path, expectedPath);
+	EXPECT_NEAR(result.shortest.totalDistance, std::sqrt(2.0), 1e-12);
+}
+
+// Tests that a negative-weight cycle is reported and leaves no shortest path.
+TEST(BellmanFordSolverTest, DetectsNegativeCycle) {
+	Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {2.0, 0.0, 0.0}},
+	    {{0, 1}, {1, 2}});
+	mesh.graph[1].push_back({0, -5.0});
+
+	const BellmanFordResult result = solveBellmanFord(mesh, 0, 2);
+
+	EXPECT_TRUE(result.negativeCycleDetected);
+	EXPECT_FALSE(result.shortest.reachable);
+	EXPECT_TRUE(result.shortest.path.empty());
+}
+
+// Tests that K shortest paths come back shortest first and without repeats.
+TEST(KShortestPathsSolverTest, ReturnsDistinctPathsInOrder) {
+	const Mesh mesh = makeSquare();
+
+	const std::vector<DijkstraResult> paths = solveKShortestPaths(mesh, 0, 2, 3);
+
+	ASSERT_EQ(paths.size(), 3u);
+	const std::vector<int> diagonal{0, 2};
+	EXPECT_EQ(paths[0].path, diagonal);
+	EXPECT_NEAR(paths[0].totalDistance, std::sqrt(2.0), 1e-12);
+	EXPECT_NE(paths[1].path, paths[2].path);
+	for (size_t i = 1; i < paths.size(); i++) {
+		EXPECT_NEAR(paths[i].totalDistance, 2.0, 1e-12);
+		EXPECT_GE(paths[i].totalDistance, paths[i - 1].totalDistance);
+	}
+}
+
+// Tests that asking for more paths than exist returns only the real ones.
+TEST(KShortestPathsSolverTest, StopsWhenPathsRunOut) {
+	const Mesh mesh = makeMesh(
+	    {{0.0, 0.0, 0.0}, {1.0, 0.0, 0.0}, {2.0, 0.0, 0.0}},
+	    {{0, 1}, {1, 2}});
+
+	EXPECT_EQ(solveKShortestPaths(mesh, 0, 2, 5).size(), 1u);
+	EXPECT_TRUE(solveKShortestPaths(mesh, 0, 2, 0).empty());
+}